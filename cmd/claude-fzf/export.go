@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jh3/claude-fzf/internal/export"
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+func exportCommand(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "output format: "+strings.Join(export.Formats, ", ")+" (default: from -o extension, else md)")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	noToolOutput := fs.Bool("no-tool-output", false, "omit tool results")
+	noThinking := fs.Bool("no-thinking", false, "omit thinking blocks")
+	subagents := fs.Bool("subagents", false, "expand subagent runs inline")
+	positional := parseInterspersed(fs, args)
+
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude-fzf export <id-or-prefix> [--format md|html|txt] [-o file] [--no-tool-output] [--no-thinking] [--subagents]")
+		os.Exit(1)
+	}
+
+	opts := export.Options{
+		Format:       *format,
+		NoToolOutput: *noToolOutput,
+		NoThinking:   *noThinking,
+		Subagents:    *subagents,
+	}
+	if opts.Format == "" {
+		opts.Format = export.FormatFromPath(*output)
+	}
+	if err := export.CheckFormat(opts.Format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	s, err := findByPrefix(loadAllSessions(), positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		err = export.Session(os.Stdout, *s, opts)
+	} else {
+		err = exportToFile(*output, *s, opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting session: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// exportToFile writes the export to path. If the export or the write
+// fails the file is removed, so no partial export is left behind; paths
+// that aren't regular files, such as /dev/stdout, are left alone.
+func exportToFile(path string, s session.Session, opts export.Options) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	regular := err == nil && info.Mode().IsRegular()
+
+	err = export.Session(f, s, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && regular {
+		os.Remove(path)
+	}
+	return err
+}
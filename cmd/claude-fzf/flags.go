@@ -0,0 +1,18 @@
+package main
+
+import "flag"
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments (flag.Parse stops at the first positional one) and
+// returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
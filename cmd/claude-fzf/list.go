@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/config"
+	"github.com/jh3/claude-fzf/internal/query"
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+func listSessions(showAll bool, args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print a JSON array of sessions")
+	asJSONL := fs.Bool("jsonl", false, "print one JSON object per session")
+	format := fs.String("format", "", "Go text/template applied to each session")
+	project := fs.String("project", "", "only sessions in this project path, glob or name")
+	branch := fs.String("branch", "", "only sessions on this git branch (glob allowed)")
+	model := fs.String("model", "", "only sessions that used this model (substring or glob, e.g. opus)")
+	since := fs.String("since", "", "only sessions active since this age or date (e.g. 3d, 2026-01-01)")
+	until := fs.String("until", "", "only sessions active before this age or date")
+	minMessages := fs.Int("min-messages", 0, "only sessions with at least this many messages")
+	limit := fs.Int("limit", 0, "print at most this many sessions")
+	positional := parseInterspersed(fs, args)
+
+	// Positional arguments use the picker's query syntax; flags override
+	// the matching qualifiers
+	now := time.Now()
+	q, err := query.Parse(strings.Join(positional, " "), now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	filter := &q.Filter
+	if *project != "" {
+		filter.Project = *project
+	}
+	if *branch != "" {
+		filter.Branch = *branch
+	}
+	if *model != "" {
+		filter.Model = *model
+	}
+	if *minMessages > 0 {
+		filter.MinMessages = *minMessages
+	}
+	for _, t := range []struct {
+		value string
+		dest  *time.Time
+	}{{*since, &filter.Since}, {*until, &filter.Until}} {
+		if t.value == "" {
+			continue
+		}
+		parsed, err := query.ParseTime(t.value, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		*t.dest = parsed
+	}
+
+	formats := 0
+	for _, set := range []bool{*asJSON, *asJSONL, *format != ""} {
+		if set {
+			formats++
+		}
+	}
+	if formats > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --json, --jsonl and --format are mutually exclusive")
+		os.Exit(1)
+	}
+
+	var sessions []session.Session
+	for _, s := range loadSessions(showAll) {
+		if _, ok := q.Match(s); ok {
+			sessions = append(sessions, s)
+		}
+	}
+	if *limit > 0 && len(sessions) > *limit {
+		sessions = sessions[:*limit]
+	}
+
+	cfg = config.Load()
+	switch {
+	case *asJSON:
+		err = printJSON(listEntries(sessions, cfg.Prices))
+	case *asJSONL:
+		err = printJSONL(listEntries(sessions, cfg.Prices))
+	case *format != "":
+		err = printTemplate(listEntries(sessions, cfg.Prices), *format)
+	default:
+		printPlain(sessions)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printPlain prints the legacy id|date|path|summary format
+func printPlain(sessions []session.Session) {
+	for _, s := range sessions {
+		fmt.Printf("%s|%s|%s|%s\n",
+			s.ID,
+			s.LastActivityAt.Format("2006-01-02 15:04"),
+			s.ProjectPath,
+			s.Summary)
+	}
+}
+
+// listEntry is a session plus fields derived from configuration, as
+// printed by --json, --jsonl and --format
+type listEntry struct {
+	session.Session
+	DurationSecs  int64   `json:"duration_seconds"` // LastActivityAt - StartedAt
+	EstimatedCost float64 `json:"estimated_cost_usd"`
+	CostComplete  bool    `json:"estimated_cost_complete"` // false if some models are unpriced
+}
+
+func listEntries(sessions []session.Session, prices config.Prices) []listEntry {
+	entries := make([]listEntry, len(sessions))
+	for i, s := range sessions {
+		entries[i].Session = s
+		entries[i].DurationSecs = int64(s.Duration().Seconds())
+		entries[i].EstimatedCost, entries[i].CostComplete = prices.Cost(s.ModelUsage)
+	}
+	return entries
+}
+
+func printJSON(sessions []listEntry) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sessions)
+}
+
+func printJSONL(sessions []listEntry) error {
+	enc := json.NewEncoder(os.Stdout)
+	for _, s := range sessions {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTemplate executes a text/template for each session, one per line
+func printTemplate(sessions []listEntry, format string) error {
+	tmpl, err := template.New("list").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	for _, s := range sessions {
+		if err := tmpl.Execute(os.Stdout, s); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
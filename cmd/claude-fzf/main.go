@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"sort"
+	"strings"
 
 	"github.com/jh3/claude-fzf/internal/cache"
 	"github.com/jh3/claude-fzf/internal/config"
 	"github.com/jh3/claude-fzf/internal/session"
 	"github.com/jh3/claude-fzf/internal/tmux"
+	"github.com/jh3/claude-fzf/internal/trash"
 	"github.com/jh3/claude-fzf/internal/ui"
+	"github.com/jh3/claude-fzf/internal/watch"
 )
 
 var cfg *config.Config
@@ -33,7 +37,15 @@ func main() {
 	if len(filtered) > 0 {
 		switch filtered[0] {
 		case "list":
-			listSessions(showAll)
+			listSessions(showAll, filtered[1:])
+		case "resume":
+			resumeCommand(showAll, filtered[1:])
+		case "stats":
+			statsCommand(showAll, filtered[1:])
+		case "export":
+			exportCommand(filtered[1:])
+		case "trash":
+			trashCommand(filtered[1:])
 		case "clear-cache":
 			clearCache()
 		case "-h", "--help":
@@ -53,7 +65,36 @@ Usage: claude-fzf [flags] [command]
 
 Commands:
   (none)        Interactive session picker
-  list          Print all sessions (for scripting)
+  list [QUERY]  Print sessions (for scripting), optionally matching QUERY
+                  --json           JSON array with every session field
+                  --jsonl          One JSON object per line
+                  --format TMPL    Go text/template per session, e.g. '{{.ID}} {{.Summary}}'
+                  --project P      Project path (incl. subdirs), glob or name
+                  --branch B       Git branch name or glob
+                  --model M        Model name substring or glob (e.g. opus)
+                  --since T        Active since T (e.g. 3d, 2w, 12h, 2026-01-01)
+                  --until T        Active before T
+                  --min-messages N At least N messages
+                  --limit N        At most N sessions
+  resume ID     Resume a session by ID or unique ID prefix
+  resume --last Resume the most recent session in the current directory
+  stats         Usage analytics: sessions per project, activity per day and
+                week, busiest branches, longest sessions, disk and token usage
+                  --json           Print the report as JSON
+                  --project P      Only this project path, glob or name
+                  --since T        Only sessions active since T
+                  --top N          Rows per table (default 10)
+  export ID     Export a session transcript
+                  --format F       md, html or txt (default: from -o, else md)
+                  -o FILE          Write to FILE instead of stdout
+                  --no-tool-output Omit tool results
+                  --no-thinking    Omit thinking blocks
+                  --subagents      Expand subagent runs inline
+  trash list    List deleted sessions
+  trash restore ID
+                Restore a deleted session by ID or unique ID prefix
+  trash empty [--older-than 30d]
+                Permanently delete trashed sessions
   clear-cache   Clear the session cache
   -h, --help    Show this help
 
@@ -62,11 +103,36 @@ Flags:
 
 Keybindings (in picker):
   Enter         Resume selected session
-  Ctrl-D        Delete selected session (with confirmation)
+  Tab/Shift-Tab Mark session and move down/up (session view)
+  Alt-A         Mark/unmark all visible sessions (session view)
+  Ctrl-D        Move marked sessions, or the selected one, to trash (with confirmation)
+  Ctrl-Z        Undo the most recent delete
+  Ctrl-R        Show/hide earlier sessions of a resumed conversation (session view)
   Ctrl-A        Toggle showing empty sessions
+  Ctrl-F        Toggle searching conversation content
+  Ctrl-T        Toggle transcript preview
+  Ctrl-O        Expand/collapse subagent runs in the transcript
+  Ctrl-B        Previous conversation branch in the transcript
+  Shift-Up/Down Scroll transcript preview
   Ctrl-N        Create new project
   Ctrl-C/Esc    Cancel
 
+Query qualifiers (picker filter and list QUERY):
+  branch:NAME   Git branch, glob allowed (branch:feature/*)
+  model:M       Model substring or glob (model:opus, model:claude-sonnet-4*)
+  path:P        Project path, glob or name (path:~/work)
+  root:NAME     Session root, glob allowed (root:work)
+  after:T       Active since T (after:2w, after:2026-01-01)
+  before:T      Active before T
+  msgs>N        Message count (also >=, <, <=, :)
+
+Filter syntax (fzf extended):
+  abc           Fuzzy match
+  'abc          Exact substring
+  ^abc / abc$   Prefix / suffix
+  !abc          Exclude
+  a | b         Either term
+
 Tmux Integration:
   When running inside tmux, selecting a session will:
   - Create a tmux session named after the project (if new)
@@ -75,6 +141,10 @@ Tmux Integration:
 
 Configuration:
   Config file: %s
+  Trash:       %s
+
+  Sessions are read from $CLAUDE_CONFIG_DIR (default ~/.claude) and any
+  roots in the config file, and resumed with their own CLAUDE_CONFIG_DIR.
 
   Example config:
     tmux:
@@ -83,14 +153,38 @@ Configuration:
         - name: edit
         - name: tests
           command: npm test -- --watch
-`, config.Path())
+`, config.Path(), trash.New().Dir())
 }
 
 func runInteractive(showAll bool) {
 	cfg = config.Load()
-	sessions := loadAllSessions()
+	scanner := newScanner()
+
+	// Without a watcher (inotify limits, say) the picker doesn't refresh
+	// once the startup scan is done
+	w, _ := watch.New(scanner.Dirs()...)
+
+	first, updates, stop, err := streamSessions(scanner, cache.New(), w)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning sessions: %v\n", err)
+		os.Exit(1)
+	}
+	if len(first.Sessions) == 0 && first.Parsed == first.Total {
+		updates = nil // nothing more is coming
+	}
 
-	result, err := ui.SelectSession(sessions, showAll, cfg.ProjectsDir)
+	result, err := ui.SelectSession(first.Sessions, ui.Options{
+		ShowEmpty:   showAll,
+		ProjectsDir: cfg.ProjectsDir,
+		Index:       cache.NewIndex(),
+		Trash:       trash.New(),
+		Prices:      cfg.Prices,
+		Updates:     updates,
+	})
+	if w != nil {
+		w.Close()
+	}
+	stop()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -123,7 +217,12 @@ func resumeInTmux(s *session.Session) {
 	}
 
 	sessionName := tmux.ProjectToSessionName(s.ProjectPath)
-	claudeCmd := fmt.Sprintf("claude --resume %s", s.ID)
+	// The tmux server's environment may carry a CLAUDE_CONFIG_DIR for
+	// another root, so set or clear it for the session's own
+	claudeCmd := fmt.Sprintf("env -u CLAUDE_CONFIG_DIR claude --resume %s", s.ID)
+	if s.ConfigDir != "" {
+		claudeCmd = fmt.Sprintf("CLAUDE_CONFIG_DIR=%q claude --resume %s", s.ConfigDir, s.ID)
+	}
 
 	if !mgr.SessionExists(sessionName) {
 		// Check if we can repurpose the current session
@@ -182,12 +281,29 @@ func resumeDirectly(s *session.Session) {
 	}
 
 	cmd := exec.Command("claude", "--resume", s.ID)
+	cmd.Env = resumeEnv(s)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Run()
 }
 
+// resumeEnv returns the environment to resume s in. A session can only be
+// resumed from the config dir it was saved in, so an inherited
+// CLAUDE_CONFIG_DIR is replaced, or removed for sessions in ~/.claude.
+func resumeEnv(s *session.Session) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "CLAUDE_CONFIG_DIR=") {
+			env = append(env, kv)
+		}
+	}
+	if s.ConfigDir != "" {
+		env = append(env, "CLAUDE_CONFIG_DIR="+s.ConfigDir)
+	}
+	return env
+}
+
 func createNewProject(projectPath string) {
 	// Check if path already exists
 	if _, err := os.Stat(projectPath); err == nil {
@@ -273,22 +389,90 @@ func createProjectInTmux(projectPath string) {
 }
 
 func loadAllSessions() []session.Session {
-	c := cache.New()
-	scanner := session.NewScanner()
-
-	sessions, err := scanner.ScanAllCached(c)
+	sessions, err := scanSessions(context.Background(), newScanner(), cache.New())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error scanning sessions: %v\n", err)
 		os.Exit(1)
 	}
+	return sessions
+}
+
+// newScanner returns a scanner for the configured session roots
+func newScanner() *session.Scanner {
+	if cfg == nil {
+		cfg = config.Load()
+	}
+	return session.NewScanner(cfg.SessionRoots()...)
+}
+
+// scanSessions scans through the cache and saves it, returning the
+// sessions most recent first. If ctx is cancelled the scan stops early with
+// ctx's error.
+func scanSessions(ctx context.Context, scanner *session.Scanner, c *cache.Cache) ([]session.Session, error) {
+	sessions, err := scanner.ScanAllCachedProgress(ctx, c, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	c.Save()
 
 	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].ModTime.After(sessions[j].ModTime)
+		return sessions[i].LastActivityAt.After(sessions[j].LastActivityAt)
 	})
 
-	return sessions
+	return sessions, nil
+}
+
+// streamSessions scans in the background for the picker. It returns as
+// soon as the cached sessions are known; the rest arrive on updates as they
+// are parsed, followed by a rescan each time w (if any) sees session files
+// change. Only files that changed are parsed again; the rest come from the
+// cache, which is saved after each scan. stop cancels the scan or rescan
+// if one is running and saves whatever has been parsed so far, so the next
+// start doesn't parse it again.
+func streamSessions(scanner *session.Scanner, c *cache.Cache, w *watch.Watcher) (first session.Progress, updates <-chan session.Progress, stop func(), err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan session.Progress)
+	send := func(p session.Progress) {
+		select {
+		case progress <- p:
+		case <-ctx.Done():
+		}
+	}
+
+	var scanErr error
+	go func() {
+		defer close(progress)
+		_, scanErr = scanner.ScanAllCachedProgress(ctx, c, send)
+		c.Save()
+		if w == nil {
+			return
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-w.Changes():
+				if !ok {
+					return
+				}
+			}
+			if sessions, err := scanSessions(ctx, scanner, c); err == nil {
+				send(session.Progress{Sessions: sessions})
+			}
+		}
+	}()
+
+	first, ok := <-progress
+	if !ok {
+		cancel()
+		return first, nil, nil, scanErr
+	}
+	stop = func() {
+		cancel()
+		c.Save()
+	}
+	return first, progress, stop, nil
 }
 
 func loadSessions(showAll bool) []session.Session {
@@ -307,22 +491,15 @@ func loadSessions(showAll bool) []session.Session {
 	return sessions
 }
 
-func listSessions(showAll bool) {
-	sessions := loadSessions(showAll)
-	for _, s := range sessions {
-		fmt.Printf("%s|%s|%s|%s\n",
-			s.ID,
-			s.ModTime.Format("2006-01-02 15:04"),
-			s.ProjectPath,
-			s.Summary)
-	}
-}
-
 func clearCache() {
 	c := cache.New()
-	if err := c.Clear(); err != nil {
+	if err := c.Clear(); err != nil && !os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Error clearing cache: %v\n", err)
 		os.Exit(1)
 	}
+	if err := cache.NewIndex().Clear(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error clearing content index: %v\n", err)
+		os.Exit(1)
+	}
 	fmt.Println("Cache cleared.")
 }
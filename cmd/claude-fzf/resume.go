@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jh3/claude-fzf/internal/config"
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+func resumeCommand(showAll bool, args []string) {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	last := fs.Bool("last", false, "resume the most recent session in the current directory")
+	fs.Parse(args)
+
+	if *last == (fs.NArg() == 1) || fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: claude-fzf resume <id-or-prefix> | --last")
+		os.Exit(1)
+	}
+
+	cfg = config.Load()
+	sessions := loadSessions(showAll)
+
+	var s *session.Session
+	var err error
+	if *last {
+		s, err = findLastInDir(sessions)
+	} else {
+		s, err = findByPrefix(sessions, fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	resumeSession(s)
+}
+
+// findByPrefix returns the session with the given ID, or the only session
+// whose ID starts with the given prefix
+func findByPrefix(sessions []session.Session, prefix string) (*session.Session, error) {
+	var matches []*session.Session
+	for i := range sessions {
+		if sessions[i].ID == prefix {
+			return &sessions[i], nil
+		}
+		if strings.HasPrefix(sessions[i].ID, prefix) {
+			matches = append(matches, &sessions[i])
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no session matches %q", prefix)
+	case 1:
+		return matches[0], nil
+	}
+
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.ID)
+	}
+	return nil, fmt.Errorf("prefix %q is ambiguous, matches: %s", prefix, strings.Join(ids, ", "))
+}
+
+// findLastInDir returns the most recent session whose project is the current
+// directory. Sessions are expected newest first.
+func findLastInDir(sessions []session.Session) (*session.Session, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	cwd = canonicalPath(cwd)
+
+	for i := range sessions {
+		if sessions[i].ProjectPath != "" && canonicalPath(sessions[i].ProjectPath) == cwd {
+			return &sessions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no sessions found for %s", cwd)
+}
+
+// canonicalPath resolves symlinks so /tmp and /private/tmp compare equal
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/query"
+	"github.com/jh3/claude-fzf/internal/stats"
+)
+
+func statsCommand(showAll bool, args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	project := fs.String("project", "", "only sessions in this project path, glob or name")
+	since := fs.String("since", "", "only sessions active since this age or date (e.g. 30d, 2026-01-01)")
+	top := fs.Int("top", 10, "rows per table")
+	fs.Parse(args)
+
+	filter := query.Filter{Project: *project}
+	if *since != "" {
+		t, err := query.ParseTime(*since, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		filter.Since = t
+	}
+
+	report := stats.Compute(filter.Apply(loadSessions(showAll)), *top)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printStats(report)
+}
+
+func printStats(r stats.Report) {
+	fmt.Printf("Sessions:  %d in %d projects\n", r.Sessions, r.Projects)
+	fmt.Printf("Messages:  %d\n", r.Messages)
+	fmt.Printf("On disk:   %s\n", stats.FormatBytes(r.Bytes))
+	if r.Usage.Total() > 0 {
+		fmt.Printf("Tokens:    %s (input %s, output %s, cache read %s, cache write %s)\n",
+			stats.FormatCount(r.Usage.Total()),
+			stats.FormatCount(r.Usage.InputTokens),
+			stats.FormatCount(r.Usage.OutputTokens),
+			stats.FormatCount(r.Usage.CacheReadTokens),
+			stats.FormatCount(r.Usage.CacheCreationTokens))
+	}
+	if r.Sessions == 0 {
+		return
+	}
+
+	printGroups("Projects", "PROJECT", r.ByProject, r.Usage.Total() > 0, shortenHome)
+	printGroups("Days", "DAY", r.ByDay, r.Usage.Total() > 0, nil)
+	printGroups("Weeks", "WEEK", r.ByWeek, r.Usage.Total() > 0, nil)
+
+	if len(r.Branches) > 0 {
+		fmt.Println("\nBranches")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BRANCH\tPROJECT\tSESSIONS\tMESSAGES")
+		for _, b := range r.Branches {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", b.Key, filepath.Base(b.Project), b.Sessions, b.Messages)
+		}
+		w.Flush()
+	}
+
+	fmt.Println("\nLongest sessions")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tMESSAGES\tSIZE\tPROJECT\tSUMMARY")
+	for _, s := range r.Longest {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", shortID(s.ID), s.Messages, stats.FormatBytes(s.Bytes), filepath.Base(s.Project), s.Summary)
+	}
+	w.Flush()
+}
+
+// printGroups prints one breakdown table, with a tokens column when usage
+// was recorded
+func printGroups(title, keyHeader string, groups []stats.Group, tokens bool, formatKey func(string) string) {
+	fmt.Printf("\n%s\n", title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := keyHeader + "\tSESSIONS\tMESSAGES\tSIZE"
+	if tokens {
+		header += "\tTOKENS"
+	}
+	fmt.Fprintln(w, header)
+	for _, g := range groups {
+		key := g.Key
+		if formatKey != nil {
+			key = formatKey(key)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s", key, g.Sessions, g.Messages, stats.FormatBytes(g.Bytes))
+		if tokens {
+			fmt.Fprintf(w, "\t%s", stats.FormatCount(g.Usage.Total()))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+func shortenHome(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, home) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
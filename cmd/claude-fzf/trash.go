@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/query"
+	"github.com/jh3/claude-fzf/internal/trash"
+)
+
+func trashCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude-fzf trash list | restore <id> | empty [--older-than 30d]")
+		os.Exit(1)
+	}
+
+	t := trash.New()
+	var err error
+	switch args[0] {
+	case "list":
+		err = trashList(t)
+	case "restore":
+		err = trashRestore(t, args[1:])
+	case "empty":
+		err = trashEmpty(t, args[1:])
+	default:
+		err = fmt.Errorf("unknown trash command %q", args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// trashList prints id|deleted|path|summary, like list
+func trashList(t *trash.Trash) error {
+	items, err := t.List()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		fmt.Printf("%s|%s|%s|%s\n",
+			item.SessionID,
+			item.DeletedAt.Format("2006-01-02 15:04"),
+			item.ProjectPath,
+			item.Summary)
+	}
+	return nil
+}
+
+func trashRestore(t *trash.Trash, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: claude-fzf trash restore <id-or-prefix>")
+	}
+	item, err := t.Find(args[0])
+	if err != nil {
+		return err
+	}
+	if err := t.Restore(item); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s to %s\n", item.SessionID, item.OriginalPath)
+	return nil
+}
+
+func trashEmpty(t *trash.Trash, args []string) error {
+	fs := flag.NewFlagSet("trash empty", flag.ExitOnError)
+	olderThan := fs.String("older-than", "", "only delete items trashed before this age or date (e.g. 30d)")
+	fs.Parse(args)
+
+	var cutoff time.Time
+	if *olderThan != "" {
+		var err error
+		if cutoff, err = query.ParseTime(*olderThan, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	items, err := t.List()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, item := range items {
+		if !cutoff.IsZero() && !item.DeletedAt.Before(cutoff) {
+			continue
+		}
+		if err := t.Remove(item); err != nil {
+			return err
+		}
+		removed++
+	}
+	fmt.Printf("Permanently deleted %d sessions.\n", removed)
+	return nil
+}
@@ -12,10 +12,23 @@ import (
 
 const cacheFileName = "sessions.cache"
 
-// Entry stores cached session data with its file mtime
+// cacheVersion is bumped whenever parsing changes, so stale entries from an
+// older binary are discarded instead of being served until the file changes
+const cacheVersion = 18
+
+// Entry stores cached session data with its file mtime. Large files also
+// keep the parser's checkpoint, so lines appended later can be parsed
+// without rereading the rest of the file.
 type Entry struct {
-	ModTime time.Time
-	Session session.Session
+	ModTime    time.Time
+	Session    session.Session
+	Checkpoint *session.Checkpoint
+}
+
+// cacheFile is the on-disk representation of the cache
+type cacheFile struct {
+	Version int
+	Entries map[string]Entry
 }
 
 // Cache manages session metadata caching
@@ -25,14 +38,18 @@ type Cache struct {
 	mu      sync.RWMutex
 }
 
-// New creates or loads a cache
-func New() *Cache {
+// cacheDir returns the directory holding all cache files, creating it if needed
+func cacheDir() string {
 	home, _ := os.UserHomeDir()
-	cacheDir := filepath.Join(home, ".cache", "claude-fzf")
-	os.MkdirAll(cacheDir, 0755)
+	dir := filepath.Join(home, ".cache", "claude-fzf")
+	os.MkdirAll(dir, 0755)
+	return dir
+}
 
+// New creates or loads a cache
+func New() *Cache {
 	c := &Cache{
-		path:    filepath.Join(cacheDir, cacheFileName),
+		path:    filepath.Join(cacheDir(), cacheFileName),
 		entries: make(map[string]Entry),
 	}
 	c.load()
@@ -45,20 +62,35 @@ func (c *Cache) load() {
 		return
 	}
 	defer f.Close()
-	gob.NewDecoder(f).Decode(&c.entries)
+
+	var cf cacheFile
+	if gob.NewDecoder(f).Decode(&cf) != nil || cf.Version != cacheVersion || cf.Entries == nil {
+		return
+	}
+	c.entries = cf.Entries
 }
 
-// Save persists the cache to disk
+// Save persists the cache to disk. It may be called while sessions are
+// still being parsed; the file is replaced whole, so a reader (or an exit
+// partway through) never sees half of it.
 func (c *Cache) Save() error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	f, err := os.Create(c.path)
+	f, err := os.CreateTemp(filepath.Dir(c.path), cacheFileName+".*")
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	return gob.NewEncoder(f).Encode(c.entries)
+	defer os.Remove(f.Name())
+
+	if err := gob.NewEncoder(f).Encode(cacheFile{Version: cacheVersion, Entries: c.entries}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path)
 }
 
 // Get retrieves a cached session if mtime matches
@@ -76,11 +108,20 @@ func (c *Cache) Get(path string, mtime time.Time) (session.Session, bool) {
 	return entry.Session, true
 }
 
-// Set stores a session in the cache
-func (c *Cache) Set(path string, mtime time.Time, sess session.Session) {
+// Checkpoint returns where the last parse of a file stopped, whatever its
+// mtime, or nil if there is none
+func (c *Cache) Checkpoint(path string) *session.Checkpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries[path].Checkpoint
+}
+
+// Set stores a session in the cache, with the checkpoint to resume from
+// when the file grows (nil if it shouldn't be resumed)
+func (c *Cache) Set(path string, mtime time.Time, sess session.Session, cp *session.Checkpoint) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.entries[path] = Entry{ModTime: mtime, Session: sess}
+	c.entries[path] = Entry{ModTime: mtime, Session: sess, Checkpoint: cp}
 }
 
 // Prune removes entries for files that no longer exist
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+const indexFileName = "content.index"
+
+// indexVersion is bumped whenever text extraction changes
+const indexVersion = 1
+
+// indexEntry stores the lowercased conversation text of one session file
+type indexEntry struct {
+	ModTime time.Time
+	Text    string
+}
+
+// indexFile is the on-disk representation of the index
+type indexFile struct {
+	Version int
+	Entries map[string]indexEntry
+}
+
+// Index is a full-text index of session conversations, invalidated by file
+// mtime the same way as Cache
+type Index struct {
+	path    string
+	entries map[string]indexEntry
+	mu      sync.RWMutex
+	loaded  sync.Once
+}
+
+// NewIndex returns the content index. It is read from disk by the first
+// Update rather than here, since it holds the text of every conversation
+// and is only needed once content search is used.
+func NewIndex() *Index {
+	return &Index{
+		path:    filepath.Join(cacheDir(), indexFileName),
+		entries: make(map[string]indexEntry),
+	}
+}
+
+func (idx *Index) load() {
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var fi indexFile
+	if gob.NewDecoder(f).Decode(&fi) != nil || fi.Version != indexVersion || fi.Entries == nil {
+		return
+	}
+	idx.mu.Lock()
+	idx.entries = fi.Entries
+	idx.mu.Unlock()
+}
+
+// Save persists the index to disk. Like Cache.Save, it writes a temporary
+// file and renames it over the old one.
+func (idx *Index) Save() error {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	f, err := os.CreateTemp(filepath.Dir(idx.path), indexFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := gob.NewEncoder(f).Encode(indexFile{Version: indexVersion, Entries: idx.entries}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), idx.path)
+}
+
+// Update re-indexes sessions whose file changed and, if sessions is
+// complete (every session there is, not a scan still in progress), drops
+// entries for files that are gone. The index is saved if anything changed.
+// Files are read by a pool of workers, one per CPU.
+func (idx *Index) Update(sessions []session.Session, complete bool) error {
+	idx.loaded.Do(idx.load)
+
+	valid := make(map[string]bool, len(sessions))
+	var stale []session.Session
+	for _, s := range sessions {
+		valid[s.FilePath] = true
+
+		idx.mu.RLock()
+		entry, ok := idx.entries[s.FilePath]
+		idx.mu.RUnlock()
+		if !ok || !entry.ModTime.Equal(s.ModTime) {
+			stale = append(stale, s)
+		}
+	}
+
+	jobs := make(chan session.Session)
+	var wg sync.WaitGroup
+	for i := 0; i < min(runtime.GOMAXPROCS(0), len(stale)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range jobs {
+				text, err := session.ExtractText(s.FilePath)
+				if err != nil {
+					continue
+				}
+				idx.mu.Lock()
+				idx.entries[s.FilePath] = indexEntry{ModTime: s.ModTime, Text: strings.ToLower(text)}
+				idx.mu.Unlock()
+			}
+		}()
+	}
+	for _, s := range stale {
+		jobs <- s
+	}
+	close(jobs)
+	wg.Wait()
+
+	changed := len(stale) > 0
+	if complete {
+		idx.mu.Lock()
+		for path := range idx.entries {
+			if !valid[path] {
+				delete(idx.entries, path)
+				changed = true
+			}
+		}
+		idx.mu.Unlock()
+	}
+
+	if !changed {
+		return nil
+	}
+	return idx.Save()
+}
+
+// Contains reports whether the conversation in the given file contains
+// query, case-insensitively
+func (idx *Index) Contains(path, query string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, ok := idx.entries[path]
+	if !ok {
+		return false
+	}
+	return strings.Contains(entry.Text, strings.ToLower(query))
+}
+
+// Clear removes the index file
+func (idx *Index) Clear() error {
+	return os.Remove(idx.path)
+}
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/jh3/claude-fzf/internal/paths"
+	"github.com/jh3/claude-fzf/internal/session"
 )
 
 // Window defines a tmux window configuration
@@ -18,10 +21,18 @@ type Tmux struct {
 	Windows []Window `yaml:"windows"`
 }
 
+// Root is a Claude config directory to read sessions from
+type Root struct {
+	Name string `yaml:"name,omitempty"` // defaults to the directory name
+	Dir  string `yaml:"dir"`
+}
+
 // Config holds all configuration options
 type Config struct {
 	ProjectsDir string `yaml:"projects_dir,omitempty"`
+	Roots       []Root `yaml:"roots,omitempty"`
 	Tmux        Tmux   `yaml:"tmux"`
+	Prices      Prices `yaml:"prices,omitempty"` // merged over DefaultPrices
 }
 
 // DefaultConfig returns the default configuration
@@ -34,6 +45,7 @@ func DefaultConfig() *Config {
 				{Name: "scratch"},
 			},
 		},
+		Prices: DefaultPrices(),
 	}
 }
 
@@ -62,6 +74,26 @@ func Load() *Config {
 	return cfg
 }
 
+// SessionRoots returns the roots to read sessions from: the one Claude
+// Code uses ($CLAUDE_CONFIG_DIR, or ~/.claude) and any configured ones
+func (c *Config) SessionRoots() []session.Root {
+	def := session.DefaultRoot()
+	var roots []session.Root
+	hasDefault := false
+	for _, r := range c.Roots {
+		if r.Dir == "" {
+			continue
+		}
+		root := session.NewRoot(r.Name, paths.ExpandHome(r.Dir))
+		hasDefault = hasDefault || root.Dir == def.Dir
+		roots = append(roots, root)
+	}
+	if !hasDefault {
+		roots = append([]session.Root{def}, roots...)
+	}
+	return roots
+}
+
 // Path returns the config file path (for help text)
 func Path() string {
 	return configPath()
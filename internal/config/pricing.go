@@ -0,0 +1,71 @@
+package config
+
+import (
+	"path"
+	"strings"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input      float64 `yaml:"input"`
+	Output     float64 `yaml:"output"`
+	CacheRead  float64 `yaml:"cache_read"`
+	CacheWrite float64 `yaml:"cache_write"`
+}
+
+// Prices maps model name patterns to prices. Patterns are globs matched
+// against the full model name, e.g. claude-sonnet-4* matches
+// claude-sonnet-4-5-20250929.
+type Prices map[string]Price
+
+// DefaultPrices returns list prices for the current model families
+func DefaultPrices() Prices {
+	return Prices{
+		"claude-opus-4-5*":   {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+		"claude-opus-4*":     {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+		"claude-sonnet-4*":   {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+		"claude-3-7-sonnet*": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+		"claude-haiku-4-5*":  {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+		"claude-3-5-haiku*":  {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+	}
+}
+
+// Lookup returns the price for a model. When several patterns match, the
+// longest (most specific) one wins.
+func (p Prices) Lookup(model string) (Price, bool) {
+	best, found := "", false
+	for pattern := range p {
+		if ok, _ := path.Match(pattern, model); !ok {
+			continue
+		}
+		// Break length ties alphabetically so the result is deterministic
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	return p[best], found
+}
+
+// Cost estimates the cost of per-model usage. complete is false when some
+// usage belongs to a model without a price, in which case that usage is
+// left out of the estimate.
+func (p Prices) Cost(usage map[string]session.Usage) (cost float64, complete bool) {
+	complete = true
+	for model, u := range usage {
+		price, ok := p.Lookup(model)
+		if !ok {
+			// Placeholders like <synthetic> mark locally generated messages
+			if u.Total() > 0 && !strings.HasPrefix(model, "<") {
+				complete = false
+			}
+			continue
+		}
+		cost += (float64(u.InputTokens)*price.Input +
+			float64(u.OutputTokens)*price.Output +
+			float64(u.CacheReadTokens)*price.CacheRead +
+			float64(u.CacheCreationTokens)*price.CacheWrite) / 1e6
+	}
+	return cost, complete
+}
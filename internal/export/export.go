@@ -0,0 +1,281 @@
+// Package export renders session transcripts as Markdown, HTML or plain text.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"md", "html", "txt"}
+
+// Options controls what an export contains
+type Options struct {
+	Format       string // "md", "html" or "txt"
+	NoToolOutput bool   // omit tool results
+	NoThinking   bool   // omit thinking blocks
+	Subagents    bool   // expand subagent runs inline under the calls that started them
+}
+
+// FormatFromPath infers the format from a file extension, defaulting to md
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	case ".txt":
+		return "txt"
+	}
+	return "md"
+}
+
+// turn is a run of consecutive messages from the same role
+type turn struct {
+	role  string
+	parts []part
+}
+
+// part is one piece of a turn: text, thinking, or a tool call with its
+// result and, for calls that started a subagent, the subagent's turns
+type part struct {
+	kind     string // "text", "thinking" or "tool"
+	text     string
+	call     session.ToolCall
+	result   *session.ToolResult
+	subagent []turn
+}
+
+// Session loads the active branch of a session's transcript, and its
+// subagent transcripts if requested, and writes it in the requested format
+func Session(w io.Writer, s session.Session, opts Options) error {
+	messages, err := session.LoadTranscript(s.FilePath)
+	if err != nil {
+		return err
+	}
+	messages = session.ActiveThread(messages)
+
+	var subagents map[string][]session.Message
+	if opts.Subagents {
+		subagents = make(map[string][]session.Message)
+		for callID, sub := range session.MatchSubagents(messages, s.Subagents) {
+			sm, err := session.LoadTranscript(sub.FilePath)
+			if err != nil {
+				return err
+			}
+			subagents[callID] = sm
+		}
+	}
+	return Render(w, s, messages, subagents, opts)
+}
+
+// Render writes an already loaded transcript in the requested format.
+// subagents holds subagent transcripts keyed by the tool call that started
+// them; it may be nil.
+func Render(w io.Writer, s session.Session, messages []session.Message, subagents map[string][]session.Message, opts Options) error {
+	turns := buildTurns(messages, subagents, opts)
+	switch opts.Format {
+	case "", "md":
+		return renderMarkdown(w, s, turns)
+	case "html":
+		return renderHTML(w, s, turns)
+	case "txt":
+		return renderText(w, s, turns)
+	}
+	return CheckFormat(opts.Format)
+}
+
+// CheckFormat returns an error if format isn't one Render supports
+func CheckFormat(format string) error {
+	if format == "" || slices.Contains(Formats, format) {
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// buildTurns groups messages into turns and attaches each tool result to
+// the call that produced it, so user lines carrying only results disappear
+func buildTurns(messages []session.Message, subagents map[string][]session.Message, opts Options) []turn {
+	results := make(map[string]*session.ToolResult)
+	if !opts.NoToolOutput {
+		for i := range messages {
+			for j := range messages[i].ToolResults {
+				r := &messages[i].ToolResults[j]
+				results[r.ToolUseID] = r
+			}
+		}
+	}
+
+	var turns []turn
+	for _, msg := range messages {
+		var parts []part
+		if msg.Thinking != "" && !opts.NoThinking {
+			parts = append(parts, part{kind: "thinking", text: msg.Thinking})
+		}
+		if msg.Text != "" {
+			parts = append(parts, part{kind: "text", text: msg.Text})
+		}
+		for _, call := range msg.ToolCalls {
+			p := part{kind: "tool", call: call, result: results[call.ID]}
+			if sub, ok := subagents[call.ID]; ok {
+				p.subagent = buildTurns(sub, nil, opts)
+			}
+			parts = append(parts, p)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		if n := len(turns); n > 0 && turns[n-1].role == msg.Role {
+			turns[n-1].parts = append(turns[n-1].parts, parts...)
+		} else {
+			turns = append(turns, turn{role: msg.Role, parts: parts})
+		}
+	}
+	return turns
+}
+
+func roleName(role string) string {
+	if role == "user" {
+		return "User"
+	}
+	return "Claude"
+}
+
+// metadata returns the label/value pairs shown at the top of an export
+func metadata(s session.Session) [][2]string {
+	meta := [][2]string{{"Session", s.ID}}
+	if s.ProjectPath != "" {
+		meta = append(meta, [2]string{"Project", s.ProjectPath})
+	}
+	if s.GitBranch != "" {
+		meta = append(meta, [2]string{"Branch", s.GitBranch})
+	}
+	meta = append(meta, [2]string{"Started", s.StartedAt.Format("2006-01-02 15:04")})
+	if d := s.Duration(); d >= time.Minute {
+		meta = append(meta, [2]string{"Duration", strings.TrimSuffix(d.Round(time.Minute).String(), "0s")})
+	}
+	return meta
+}
+
+// prettyInput indents a tool call's JSON input for display
+func prettyInput(input json.RawMessage) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, input, "", "  ") != nil {
+		return string(input)
+	}
+	return buf.String()
+}
+
+func renderMarkdown(w io.Writer, s session.Session, turns []turn) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", s.Summary)
+	for _, m := range metadata(s) {
+		fmt.Fprintf(&b, "- **%s:** %s\n", m[0], m[1])
+	}
+
+	writeMarkdownTurns(&b, turns, "##")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownTurns writes turns under role headings of the given level.
+// Subagent turns are nested in a collapsible block one level deeper.
+func writeMarkdownTurns(b *strings.Builder, turns []turn, heading string) {
+	for _, t := range turns {
+		fmt.Fprintf(b, "\n%s %s\n", heading, roleName(t.role))
+		for _, p := range t.parts {
+			b.WriteString("\n")
+			switch p.kind {
+			case "text":
+				b.WriteString(strings.TrimRight(p.text, "\n") + "\n")
+			case "thinking":
+				b.WriteString("> _Thinking_\n>\n")
+				for _, line := range strings.Split(strings.TrimRight(p.text, "\n"), "\n") {
+					b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+				}
+			case "tool":
+				fmt.Fprintf(b, "**Tool: %s**\n\n", p.call.Name)
+				writeFenced(b, "json", prettyInput(p.call.Input))
+				if p.subagent != nil {
+					b.WriteString("\n<details><summary>Subagent transcript</summary>\n")
+					writeMarkdownTurns(b, p.subagent, heading+"#")
+					b.WriteString("\n</details>\n")
+				}
+				if p.result != nil {
+					label := "Output"
+					if p.result.IsError {
+						label = "Error"
+					}
+					fmt.Fprintf(b, "\n<details><summary>%s</summary>\n\n", label)
+					writeFenced(b, "", p.result.Content)
+					b.WriteString("\n</details>\n")
+				}
+			}
+		}
+	}
+}
+
+// writeFenced writes a code block, using a fence longer than any run of
+// backticks in the content
+func writeFenced(b *strings.Builder, lang, content string) {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+func renderText(w io.Writer, s session.Session, turns []turn) error {
+	var b strings.Builder
+
+	b.WriteString(s.Summary + "\n")
+	for _, m := range metadata(s) {
+		fmt.Fprintf(&b, "%s: %s\n", m[0], m[1])
+	}
+
+	b.WriteString(textTurns(turns))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// textTurns renders turns as plain text. Subagent turns are indented below
+// the call that started them.
+func textTurns(turns []turn) string {
+	var b strings.Builder
+	for _, t := range turns {
+		fmt.Fprintf(&b, "\n=== %s ===\n", roleName(t.role))
+		for _, p := range t.parts {
+			switch p.kind {
+			case "text":
+				b.WriteString(strings.TrimRight(p.text, "\n") + "\n")
+			case "thinking":
+				b.WriteString("[thinking]\n" + strings.TrimRight(p.text, "\n") + "\n")
+			case "tool":
+				fmt.Fprintf(&b, "[tool: %s] %s\n", p.call.Name, string(p.call.Input))
+				if p.subagent != nil {
+					b.WriteString("[subagent]\n")
+					for _, line := range strings.Split(strings.Trim(textTurns(p.subagent), "\n"), "\n") {
+						if line != "" {
+							b.WriteString("    " + line)
+						}
+						b.WriteString("\n")
+					}
+				}
+				if p.result != nil {
+					b.WriteString("[output]\n" + strings.TrimRight(p.result.Content, "\n") + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
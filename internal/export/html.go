@@ -0,0 +1,111 @@
+package export
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+var htmlTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"role":   roleName,
+	"pretty": prettyInput,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Session.Summary}}</title>
+<style>
+body { font-family: -apple-system, system-ui, sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+.meta { color: #666; }
+.turn { margin: 1.5rem 0; }
+.turn h2 { font-size: 1rem; margin-bottom: 0.5rem; }
+.user h2 { color: #0969da; }
+.assistant h2 { color: #bf3989; }
+.text { white-space: pre-wrap; }
+.thinking { white-space: pre-wrap; color: #666; border-left: 3px solid #ddd; padding-left: 0.75rem; font-style: italic; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; }
+.error summary { color: #cf222e; }
+.subagent { border-left: 3px solid #d0d7de; padding-left: 1rem; }
+.subagent h2 { font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>{{.Session.Summary}}</h1>
+<ul class="meta">
+{{- range .Meta}}
+<li><strong>{{index . 0}}:</strong> {{index . 1}}</li>
+{{- end}}
+</ul>
+{{- template "turns" .Turns}}
+</body>
+</html>
+{{define "turns"}}
+{{- range .}}
+<section class="turn {{.Role}}">
+<h2>{{role .Role}}</h2>
+{{- range .Parts}}
+{{- if eq .Kind "text"}}
+<div class="text">{{.Text}}</div>
+{{- else if eq .Kind "thinking"}}
+<div class="thinking">{{.Text}}</div>
+{{- else if eq .Kind "tool"}}
+<p><strong>Tool: {{.Call.Name}}</strong></p>
+<pre>{{pretty .Call.Input}}</pre>
+{{- with .Subagent}}
+<details class="subagent"><summary>Subagent transcript</summary>
+{{- template "turns" .}}
+</details>
+{{- end}}
+{{- with .Result}}
+<details{{if .IsError}} class="error"{{end}}><summary>{{if .IsError}}Error{{else}}Output{{end}}</summary><pre>{{.Content}}</pre></details>
+{{- end}}
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+{{- end}}`))
+
+// htmlTurn and htmlPart expose turns to the template, which can't read
+// unexported fields
+type htmlTurn struct {
+	Role  string
+	Parts []htmlPart
+}
+
+type htmlPart struct {
+	Kind     string
+	Text     string
+	Call     session.ToolCall
+	Result   *session.ToolResult
+	Subagent []htmlTurn
+}
+
+func renderHTML(w io.Writer, s session.Session, turns []turn) error {
+	data := struct {
+		Session session.Session
+		Meta    [][2]string
+		Turns   []htmlTurn
+	}{Session: s, Meta: metadata(s)}
+
+	data.Turns = htmlTurns(turns)
+	return htmlTemplate.Execute(w, data)
+}
+
+func htmlTurns(turns []turn) []htmlTurn {
+	var out []htmlTurn
+	for _, t := range turns {
+		ht := htmlTurn{Role: t.role}
+		for _, p := range t.parts {
+			ht.Parts = append(ht.Parts, htmlPart{
+				Kind:     p.kind,
+				Text:     p.text,
+				Call:     p.call,
+				Result:   p.result,
+				Subagent: htmlTurns(p.subagent),
+			})
+		}
+		out = append(out, ht)
+	}
+	return out
+}
@@ -0,0 +1,373 @@
+// Package fuzzy implements fzf-style fuzzy matching with scoring, plus
+// fzf's extended search syntax ('exact, ^prefix, suffix$, !negate, a | b).
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring follows fzf: every matched character earns scoreMatch, gaps cost
+// points, and matches at word boundaries or camelCase humps earn bonuses.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary            = scoreMatch / 2
+	bonusNonWord             = scoreMatch / 2
+	bonusCamel               = bonusBoundary + scoreGapExtension
+	bonusConsecutive         = -(scoreGapStart + scoreGapExtension)
+	bonusFirstCharMultiplier = 2
+)
+
+type charClass int
+
+const (
+	charNonWord charClass = iota
+	charLower
+	charUpper
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsNumber(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLower
+	}
+	return charNonWord
+}
+
+// bonusFor returns the bonus for matching a character of class cur that
+// follows a character of class prev
+func bonusFor(prev, cur charClass) int {
+	switch {
+	case prev == charNonWord && cur != charNonWord:
+		return bonusBoundary
+	case prev == charLower && cur == charUpper, prev != charNumber && cur == charNumber:
+		return bonusCamel
+	case cur == charNonWord:
+		return bonusNonWord
+	}
+	return 0
+}
+
+type termKind int
+
+const (
+	termFuzzy termKind = iota
+	termExact
+	termPrefix
+	termSuffix
+	termEqual
+)
+
+// term is a single space-separated token of a query
+type term struct {
+	kind          termKind
+	inverse       bool
+	text          []rune
+	caseSensitive bool
+}
+
+// Query is a parsed search. Every group must match; a group matches when
+// any of its alternatives (joined with "|") does.
+type Query struct {
+	groups [][]term
+}
+
+// Parse parses fzf extended search syntax. Terms are case-insensitive
+// unless they contain an uppercase letter (smart case).
+func Parse(input string) Query {
+	var q Query
+	var group []term
+	afterBar := false
+
+	for _, tok := range strings.Fields(input) {
+		if tok == "|" {
+			afterBar = len(group) > 0
+			continue
+		}
+		t, ok := parseTerm(tok)
+		if !ok {
+			continue
+		}
+		if afterBar {
+			group = append(group, t)
+		} else {
+			if len(group) > 0 {
+				q.groups = append(q.groups, group)
+			}
+			group = []term{t}
+		}
+		afterBar = false
+	}
+	if len(group) > 0 {
+		q.groups = append(q.groups, group)
+	}
+	return q
+}
+
+func parseTerm(tok string) (term, bool) {
+	t := term{kind: termFuzzy}
+
+	if strings.HasPrefix(tok, "!") {
+		t.inverse = true
+		t.kind = termExact
+		tok = tok[1:]
+	}
+	if tok != "$" && strings.HasSuffix(tok, "$") {
+		t.kind = termSuffix
+		tok = tok[:len(tok)-1]
+	}
+	if strings.HasPrefix(tok, "'") {
+		// A quote flips exactness
+		switch t.kind {
+		case termFuzzy:
+			t.kind = termExact
+		case termExact:
+			t.kind = termFuzzy
+		}
+		tok = tok[1:]
+	} else if strings.HasPrefix(tok, "^") {
+		if t.kind == termSuffix {
+			t.kind = termEqual
+		} else {
+			t.kind = termPrefix
+		}
+		tok = tok[1:]
+	}
+
+	if tok == "" {
+		return term{}, false
+	}
+	t.caseSensitive = strings.ToLower(tok) != tok
+	if !t.caseSensitive {
+		tok = strings.ToLower(tok)
+	}
+	t.text = []rune(tok)
+	return t, true
+}
+
+// Empty reports whether the query has no terms and so matches everything
+func (q Query) Empty() bool {
+	return len(q.groups) == 0
+}
+
+// Match reports whether text satisfies the query. The score ranks matches
+// (higher is better) and positions are the rune indices of matched
+// characters, for highlighting.
+func (q Query) Match(text string) (score int, positions []int, ok bool) {
+	runes := []rune(text)
+	for _, group := range q.groups {
+		matched := false
+		for _, t := range group {
+			s, pos, hit := t.match(runes)
+			if hit == t.inverse {
+				continue
+			}
+			matched = true
+			if !t.inverse {
+				score += s
+				positions = append(positions, pos...)
+			}
+			break
+		}
+		if !matched {
+			return 0, nil, false
+		}
+	}
+	return score, normalize(positions), true
+}
+
+// Highlight returns the positions in text matched by any non-negated term.
+// Unlike Match it doesn't require the whole query to match, so it can be
+// applied to individual fields of a line.
+func (q Query) Highlight(text string) []int {
+	runes := []rune(text)
+	var positions []int
+	for _, group := range q.groups {
+		for _, t := range group {
+			if t.inverse {
+				continue
+			}
+			if _, pos, ok := t.match(runes); ok {
+				positions = append(positions, pos...)
+			}
+		}
+	}
+	return normalize(positions)
+}
+
+func normalize(positions []int) []int {
+	if len(positions) == 0 {
+		return nil
+	}
+	sort.Ints(positions)
+	out := positions[:1]
+	for _, p := range positions[1:] {
+		if p != out[len(out)-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (t term) match(text []rune) (int, []int, bool) {
+	switch t.kind {
+	case termFuzzy:
+		return t.fuzzyMatch(text)
+	case termExact:
+		return t.exactMatch(text)
+	case termPrefix:
+		start := leadingSpace(text)
+		return t.matchAt(text, start)
+	case termSuffix:
+		end := len(text) - trailingSpace(text)
+		return t.matchAt(text, end-len(t.text))
+	case termEqual:
+		start, end := leadingSpace(text), len(text)-trailingSpace(text)
+		if end-start != len(t.text) {
+			return 0, nil, false
+		}
+		return t.matchAt(text, start)
+	}
+	return 0, nil, false
+}
+
+func (t term) eq(r, p rune) bool {
+	if !t.caseSensitive {
+		r = unicode.ToLower(r)
+	}
+	return r == p
+}
+
+// fuzzyMatch finds the pattern as a subsequence: a forward pass finds the
+// first complete match, then a backward pass from its end finds the
+// tightest start, and that window is scored
+func (t term) fuzzyMatch(text []rune) (int, []int, bool) {
+	pidx, end := 0, -1
+	for i, r := range text {
+		if t.eq(r, t.text[pidx]) {
+			pidx++
+			if pidx == len(t.text) {
+				end = i + 1
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	pidx, start := len(t.text)-1, 0
+	for i := end - 1; i >= 0; i-- {
+		if t.eq(text[i], t.text[pidx]) {
+			pidx--
+			if pidx < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	score, positions := t.score(text, start, end)
+	return score, positions, true
+}
+
+// exactMatch finds the best scoring contiguous occurrence of the pattern
+func (t term) exactMatch(text []rune) (int, []int, bool) {
+	best, bestPos, found := 0, []int(nil), false
+	for i := 0; i+len(t.text) <= len(text); i++ {
+		if score, pos, ok := t.matchAt(text, i); ok && (!found || score > best) {
+			best, bestPos, found = score, pos, true
+		}
+	}
+	return best, bestPos, found
+}
+
+// matchAt matches the pattern contiguously at position start
+func (t term) matchAt(text []rune, start int) (int, []int, bool) {
+	if start < 0 || start+len(t.text) > len(text) {
+		return 0, nil, false
+	}
+	for i, p := range t.text {
+		if !t.eq(text[start+i], p) {
+			return 0, nil, false
+		}
+	}
+	score, positions := t.score(text, start, start+len(t.text))
+	return score, positions, true
+}
+
+// score greedily matches the pattern within text[start:end], awarding
+// match points and bonuses and charging for gaps
+func (t term) score(text []rune, start, end int) (int, []int) {
+	var positions []int
+	score, pidx, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+
+	prevClass := charNonWord
+	if start > 0 {
+		prevClass = classOf(text[start-1])
+	}
+
+	for i := start; i < end; i++ {
+		class := classOf(text[i])
+		if pidx < len(t.text) && t.eq(text[i], t.text[pidx]) {
+			positions = append(positions, i)
+			score += scoreMatch
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prevClass = class
+	}
+	return score, positions
+}
+
+func leadingSpace(text []rune) int {
+	n := 0
+	for n < len(text) && unicode.IsSpace(text[n]) {
+		n++
+	}
+	return n
+}
+
+func trailingSpace(text []rune) int {
+	n := 0
+	for n < len(text) && unicode.IsSpace(text[len(text)-1-n]) {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,166 @@
+// Package query selects sessions by metadata such as project, branch,
+// activity date and message count, and parses search queries that mix
+// such qualifiers with fuzzy matched text.
+package query
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/paths"
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// Filter selects sessions by metadata. Zero-valued fields don't filter.
+type Filter struct {
+	Project       string // path (including subdirectories), glob, or project name
+	Branch        string // branch name or glob, e.g. feature/*
+	Model         string // model name substring or glob, e.g. opus or claude-sonnet-4*
+	Root          string // session root name or glob
+	Since         time.Time
+	Until         time.Time
+	MinMessages   int
+	MessagesBelow int // only sessions with fewer messages than this
+}
+
+// Match reports whether a session satisfies every criterion
+func (f Filter) Match(s session.Session) bool {
+	if f.Project != "" && !matchProject(f.Project, s.ProjectPath) {
+		return false
+	}
+	if f.Branch != "" && !matchGlob(f.Branch, s.GitBranch) {
+		return false
+	}
+	if f.Model != "" && !matchModel(f.Model, s.Models) {
+		return false
+	}
+	if f.Root != "" && !matchGlob(f.Root, s.Root) {
+		return false
+	}
+	if !f.Since.IsZero() && s.LastActivityAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !s.LastActivityAt.Before(f.Until) {
+		return false
+	}
+	msgs := s.UserMsgCount + s.AsstMsgCount
+	if f.MinMessages > 0 && msgs < f.MinMessages {
+		return false
+	}
+	if f.MessagesBelow > 0 && msgs >= f.MessagesBelow {
+		return false
+	}
+	return true
+}
+
+// Apply returns the sessions that match, preserving order
+func (f Filter) Apply(sessions []session.Session) []session.Session {
+	var out []session.Session
+	for _, s := range sessions {
+		if f.Match(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// matchProject matches a project path against a glob, a path (which also
+// matches its subdirectories) or a bare project name
+func matchProject(pattern, projectPath string) bool {
+	if projectPath == "" {
+		return false
+	}
+	pattern = paths.ExpandHome(pattern)
+
+	if isGlob(pattern) {
+		if ok, _ := filepath.Match(pattern, projectPath); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			ok, _ := filepath.Match(pattern, filepath.Base(projectPath))
+			return ok
+		}
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") && pattern != "." && pattern != ".." {
+		return filepath.Base(projectPath) == pattern
+	}
+
+	if abs, err := filepath.Abs(pattern); err == nil {
+		pattern = abs
+	}
+	return projectPath == pattern || strings.HasPrefix(projectPath, pattern+string(filepath.Separator))
+}
+
+// matchGlob matches a value exactly, or as a glob when the pattern has
+// wildcards. Uses path.Match so "*" doesn't cross "/".
+func matchGlob(pattern, value string) bool {
+	if !isGlob(pattern) {
+		return pattern == value
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// matchModel reports whether any model matches the pattern, as a glob when
+// it has wildcards or else as a case-insensitive substring, so "opus"
+// matches claude-opus-4-5-20251101
+func matchModel(pattern string, models []string) bool {
+	for _, m := range models {
+		if isGlob(pattern) {
+			if matchGlob(pattern, m) {
+				return true
+			}
+		} else if strings.Contains(strings.ToLower(m), strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// dateLayouts are the absolute date formats accepted by ParseTime
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime parses either a relative age such as "90m", "12h", "3d" or "2w"
+// (meaning that long before now) or an absolute date such as "2026-01-01"
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if age, err := parseAge(value); err == nil {
+		return now.Add(-age), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want e.g. 3d, 2w, 12h or 2026-01-01)", value)
+}
+
+// parseAge parses a duration, adding d (days) and w (weeks) units to the
+// ones understood by time.ParseDuration
+func parseAge(value string) (time.Duration, error) {
+	if n := len(value); n > 1 {
+		unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[value[n-1]]
+		if unit != 0 {
+			count, err := strconv.Atoi(value[:n-1])
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,131 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/fuzzy"
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// Query is a parsed search such as "branch:feature/* after:2w msgs>10 oauth":
+// qualifiers become a Filter, everything else is fuzzy matched text
+type Query struct {
+	Filter Filter
+	Text   fuzzy.Query
+	Raw    string // the free-text part, for substring searches
+}
+
+// msgsPattern matches message count qualifiers like msgs>10 or messages<=3
+var msgsPattern = regexp.MustCompile(`^(?:msgs|messages)(>=|<=|>|<|:|=)(\d+)$`)
+
+// Parse splits input into qualifiers and free text. Supported qualifiers:
+//
+//	branch:NAME          git branch, glob allowed
+//	model:M              model name substring or glob (model:opus)
+//	path:P, project:P    project path (incl. subdirs), glob or name
+//	root:NAME            session root, glob allowed
+//	after:T, since:T     active since an age (3d, 2w) or date (2026-01-01)
+//	before:T, until:T    active before an age or date
+//	msgs>N, msgs<=N, ... message count (also >=, <, : and =)
+//
+// Qualifiers with an empty value (still being typed) are ignored. An invalid
+// qualifier is reported as an error, but the rest of the query is still
+// returned so interactive callers can keep filtering.
+func Parse(input string, now time.Time) (Query, error) {
+	var q Query
+	var text []string
+	var firstErr error
+
+	for _, tok := range strings.Fields(input) {
+		handled, err := q.Filter.applyQualifier(tok, now)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if !handled {
+			text = append(text, tok)
+		}
+	}
+
+	q.Raw = strings.Join(text, " ")
+	q.Text = fuzzy.Parse(q.Raw)
+	return q, firstErr
+}
+
+// applyQualifier applies tok to the filter if it is a known qualifier
+func (f *Filter) applyQualifier(tok string, now time.Time) (bool, error) {
+	if m := msgsPattern.FindStringSubmatch(tok); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		switch m[1] {
+		case ">":
+			f.MinMessages = n + 1
+		case ">=":
+			f.MinMessages = n
+		case "<":
+			f.MessagesBelow = n
+		case "<=":
+			f.MessagesBelow = n + 1
+		default:
+			f.MinMessages, f.MessagesBelow = n, n+1
+		}
+		return true, nil
+	}
+
+	key, value, ok := strings.Cut(tok, ":")
+	if !ok {
+		return false, nil
+	}
+
+	switch strings.ToLower(key) {
+	case "branch":
+		f.Branch = value
+	case "model":
+		f.Model = value
+	case "path", "project":
+		f.Project = value
+	case "root":
+		f.Root = value
+	case "after", "since":
+		return true, parseQualifierTime(tok, value, now, &f.Since)
+	case "before", "until":
+		return true, parseQualifierTime(tok, value, now, &f.Until)
+	case "msgs", "messages":
+		if value != "" {
+			return true, fmt.Errorf("invalid qualifier %q (want e.g. msgs>10)", tok)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func parseQualifierTime(tok, value string, now time.Time, dest *time.Time) error {
+	if value == "" {
+		return nil
+	}
+	t, err := ParseTime(value, now)
+	if err != nil {
+		return fmt.Errorf("invalid qualifier %q: %w", tok, err)
+	}
+	*dest = t
+	return nil
+}
+
+// Match reports whether a session satisfies the qualifiers and the free
+// text, with the text's match score
+func (q Query) Match(s session.Session) (int, bool) {
+	if !q.Filter.Match(s) {
+		return 0, false
+	}
+	score, _, ok := q.Text.Match(SearchText(s))
+	return score, ok
+}
+
+// SearchText is the text that free-form terms are matched against
+func SearchText(s session.Session) string {
+	return filepath.Base(s.ProjectPath) + " " + s.Summary + " " + s.GitBranch
+}
@@ -0,0 +1,48 @@
+package session
+
+// LinkContinuations sets ContinuesFrom for sessions that resumed an earlier
+// one and weren't already linked by ResolveSummaries. A file continues
+// another when its first message's parent is one of the other file's
+// messages, when it carries the other file's sessionId, or when it is a
+// copy of the same conversation (same first message) with later activity.
+// Only links to sessions with earlier activity are made, so chains can't
+// form cycles.
+func LinkContinuations(sessions []Session) {
+	byID := make(map[string]int, len(sessions))
+	leafOwner := make(map[string]int)
+	roots := make(map[string][]int)
+	for i, s := range sessions {
+		byID[s.ID] = i
+		for _, leaf := range s.LeafUUIDs {
+			leafOwner[leaf] = i
+		}
+		if s.RootUUID != "" {
+			roots[s.RootUUID] = append(roots[s.RootUUID], i)
+		}
+	}
+
+	for i := range sessions {
+		s := &sessions[i]
+		if s.ContinuesFrom != "" {
+			continue
+		}
+
+		prev := -1
+		if j, ok := leafOwner[s.ExternalParent]; ok && s.ExternalParent != "" {
+			prev = j
+		} else if j, ok := byID[s.ForeignSessionID]; ok && s.ForeignSessionID != "" {
+			prev = j
+		} else {
+			for _, j := range roots[s.RootUUID] {
+				if j != i && (prev < 0 || sessions[j].LastActivityAt.After(sessions[prev].LastActivityAt)) &&
+					sessions[j].LastActivityAt.Before(s.LastActivityAt) {
+					prev = j
+				}
+			}
+		}
+
+		if prev >= 0 && prev != i && sessions[prev].LastActivityAt.Before(s.LastActivityAt) {
+			s.ContinuesFrom = sessions[prev].ID
+		}
+	}
+}
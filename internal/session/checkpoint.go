@@ -0,0 +1,120 @@
+package session
+
+import (
+	"hash/fnv"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// checkpointMinSize is the smallest file ParseFileFrom returns a
+	// checkpoint for. Smaller files reparse in a few milliseconds, so their
+	// checkpoints would only bloat the cache.
+	checkpointMinSize = 1 << 20
+
+	// checksumSize is how much of each end of the parsed prefix is hashed
+	// to tell an appended file from a rewritten one
+	checksumSize = 4096
+)
+
+// Checkpoint is where ParseFileFrom stopped reading a file and what it had
+// seen so far, so that a file that has since grown can be parsed from
+// there. Branch-independent totals (activity times, token usage,
+// summaries) are kept in Base; everything that depends on which branch is
+// active is kept per line, since an appended message can move the active
+// branch.
+type Checkpoint struct {
+	Offset    int64  // bytes parsed, always just past a newline
+	LineCount int    // lines parsed, for numbering warnings
+	HeadSum   uint64 // hash of the first bytes of the file
+	TailSum   uint64 // hash of the bytes just before Offset
+	Base      Session
+	Lines     []lineRecord
+	UsageIDs  map[string]bool // message IDs whose usage has been counted
+}
+
+// lineRecord is the part of a line whose contribution depends on whether
+// it is on the active branch
+type lineRecord struct {
+	Type      string
+	UUID      string
+	Parent    string
+	Cwd       string
+	GitBranch string
+	Model     string
+	Text      string // user text; truncated to the summary length in session files
+	Results   int    // tool results on a user line
+	Calls     int    // tool calls on an assistant line
+	Day       string // local date of the line's timestamp
+	Skimmed   bool   // too long to read; only its ids, type and timestamp are known
+}
+
+func (r lineRecord) isMessage() bool {
+	return r.Type == "user" || r.Type == "assistant"
+}
+
+func newCheckpoint(path string) *Checkpoint {
+	return &Checkpoint{
+		Base: Session{
+			ID:       strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+			FilePath: path,
+		},
+		UsageIDs: make(map[string]bool),
+	}
+}
+
+// resumable reports whether f, now size bytes long, still starts with the
+// bytes cp was taken from, meaning lines have only been appended
+func (cp *Checkpoint) resumable(f *os.File, size int64) bool {
+	if cp == nil || cp.Offset == 0 || size < cp.Offset {
+		return false
+	}
+	head, tail, err := checksums(f, cp.Offset)
+	return err == nil && head == cp.HeadSum && tail == cp.TailSum
+}
+
+// seal records the checksums of the parsed prefix of f
+func (cp *Checkpoint) seal(f *os.File) error {
+	var err error
+	cp.HeadSum, cp.TailSum, err = checksums(f, cp.Offset)
+	return err
+}
+
+// clone returns a copy of cp that can be parsed into without changing cp,
+// which may be shared with the cache
+func (cp *Checkpoint) clone() *Checkpoint {
+	c := *cp
+	c.Base.ModelUsage = maps.Clone(cp.Base.ModelUsage)
+	c.Base.LeafSummaries = maps.Clone(cp.Base.LeafSummaries)
+	c.Base.SummaryLeaves = slices.Clip(cp.Base.SummaryLeaves)
+	c.Base.Days = maps.Clone(cp.Base.Days)
+	c.Base.Warnings = slices.Clip(cp.Base.Warnings)
+	c.Lines = slices.Clip(cp.Lines)
+	c.UsageIDs = maps.Clone(cp.UsageIDs)
+	if c.UsageIDs == nil {
+		c.UsageIDs = make(map[string]bool)
+	}
+	return &c
+}
+
+// checksums hashes the first and last few KB of the first n bytes of f
+func checksums(f *os.File, n int64) (head, tail uint64, err error) {
+	size := min(n, checksumSize)
+	if head, err = checksum(io.NewSectionReader(f, 0, size)); err != nil {
+		return 0, 0, err
+	}
+	tail, err = checksum(io.NewSectionReader(f, n-size, size))
+	return head, tail, err
+}
+
+func checksum(r io.Reader) (uint64, error) {
+	h := fnv.New64a()
+	if _, err := io.Copy(h, r); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
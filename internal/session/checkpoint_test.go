@@ -0,0 +1,167 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// message returns a JSONL line for a user or assistant message
+func message(typ, uuid, parent, text string, minute int) string {
+	m := map[string]any{
+		"type":      typ,
+		"uuid":      uuid,
+		"sessionId": "s",
+		"cwd":       "/tmp/project",
+		"timestamp": timestamp(minute),
+		"message":   map[string]any{"role": typ, "content": text},
+	}
+	if parent != "" {
+		m["parentUuid"] = parent
+	}
+	return marshalLine(m)
+}
+
+// response returns an assistant line that is one content block of the API
+// response id, carrying that response's usage
+func response(uuid, parent, id string, minute int) string {
+	return marshalLine(map[string]any{
+		"type":       "assistant",
+		"uuid":       uuid,
+		"parentUuid": parent,
+		"sessionId":  "s",
+		"timestamp":  timestamp(minute),
+		"message": map[string]any{
+			"id":      id,
+			"role":    "assistant",
+			"model":   "claude-sonnet-4",
+			"content": []map[string]any{{"type": "text", "text": "reply " + uuid}},
+			"usage":   map[string]any{"input_tokens": 10, "output_tokens": 20, "cache_read_input_tokens": 30},
+		},
+	})
+}
+
+func marshalLine(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data) + "\n"
+}
+
+func timestamp(minute int) string {
+	return fmt.Sprintf("2025-01-01T10:%02d:00Z", minute)
+}
+
+func TestParseFileFromMatchesParseFile(t *testing.T) {
+	// Sessions under checkpointMinSize get no checkpoint, so every file
+	// starts with a long first prompt
+	head := message("user", "a", "", strings.Repeat("x", checkpointMinSize), 0) +
+		response("b", "a", "msg_1", 1) +
+		message("user", "c", "b", "next", 2)
+
+	tests := []struct {
+		name    string
+		appends []string // written one at a time, parsing from the last checkpoint after each
+	}{
+		{
+			name: "appended messages",
+			appends: []string{
+				response("d", "c", "msg_2", 3) + message("user", "e", "d", "more", 4),
+				response("f", "e", "msg_3", 5),
+			},
+		},
+		{
+			name: "branch moves",
+			appends: []string{
+				response("d", "c", "msg_2", 3),
+				// An edit of c starts a branch from b, which becomes active
+				message("user", "c2", "b", "edited", 4) + response("d2", "c2", "msg_3", 5),
+				// and a rewind goes back to the first branch
+				message("user", "e", "d", "back", 6),
+			},
+		},
+		{
+			name: "repeated usage ids",
+			appends: []string{
+				// More blocks of the response before the checkpoint
+				response("b2", "b", "msg_1", 1),
+				response("d", "c", "msg_2", 3) + response("d2", "d", "msg_2", 3),
+			},
+		},
+		{
+			name: "warnings",
+			appends: []string{
+				"{not json\n" + response("d", "c", "msg_2", 3),
+				"\n" + `{"type": 5}` + "\n",
+			},
+		},
+		{
+			name: "unterminated last line",
+			appends: func() []string {
+				last := response("d", "c", "msg_2", 3)
+				half := len(last) / 2
+				return []string{last[:half], last[half:], message("user", "e", "d", "done", 4)}
+			}(),
+		},
+		{
+			name: "summary",
+			appends: []string{
+				marshalLine(map[string]any{"type": "summary", "summary": "Fixing the parser", "leafUuid": "c"}),
+				response("d", "c", "msg_2", 3),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "s.jsonl")
+			if err := os.WriteFile(path, []byte(head), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, cp, err := ParseFileFrom(path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cp == nil {
+				t.Fatal("no checkpoint for a complete file over checkpointMinSize")
+			}
+
+			for i, text := range tt.appends {
+				appendFile(t, path, text)
+				got, next, err := ParseFileFrom(path, cp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, err := ParseFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("after append %d:\nfrom checkpoint: %+v\nfull parse:      %+v", i+1, got, want)
+				}
+				// A file that doesn't end in a complete line has no
+				// checkpoint; the next parse starts from the last one
+				if next != nil {
+					cp = next
+				}
+			}
+		})
+	}
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
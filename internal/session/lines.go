@@ -0,0 +1,228 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxLineSize is the longest line that is parsed. Lines holding big tool
+// results, pasted files or images routinely run past a megabyte; longer
+// than this and the line is skipped rather than held in memory.
+const maxLineSize = 64 << 20
+
+// maxWarnings is how many parse warnings a session keeps
+const maxWarnings = 10
+
+// lineReader reads JSONL lines of any length. Unlike bufio.Scanner it
+// doesn't stop at a long line: lines over maxLineSize are read past and
+// reported as too long, and reading carries on with the next one.
+type lineReader struct {
+	r          *bufio.Reader
+	line       []byte
+	tooLong    bool     // the current line was over maxLineSize and isn't in line
+	skim       *skimmer // the top-level fields of a line that was too long
+	size       int64    // length of the current line, including its newline
+	terminated bool     // the current line ended in a newline
+	err        error
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// next advances to the next line. It returns false at the end of the input
+// or on a read error, which err then returns.
+func (lr *lineReader) next() bool {
+	lr.line = lr.line[:0]
+	lr.tooLong = false
+	lr.skim = nil
+	lr.size = 0
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		lr.size += int64(len(chunk))
+		switch {
+		case lr.tooLong:
+			lr.skim.feed(chunk)
+		case len(lr.line)+len(chunk) > maxLineSize:
+			lr.tooLong = true
+			lr.skim = newSkimmer()
+			lr.skim.feed(lr.line)
+			lr.skim.feed(chunk)
+			lr.line = lr.line[:0]
+		default:
+			lr.line = append(lr.line, chunk...)
+		}
+
+		switch err {
+		case bufio.ErrBufferFull:
+			continue
+		case nil:
+			lr.terminated = true
+			lr.line = bytes.TrimSuffix(bytes.TrimSuffix(lr.line, []byte("\n")), []byte("\r"))
+			return true
+		case io.EOF:
+			lr.terminated = false
+			return lr.size > 0
+		default:
+			lr.err = err
+			return false
+		}
+	}
+}
+
+// bytes returns the current line without its line ending. It is only valid
+// until the next call to next, and empty for a line that was too long.
+func (lr *lineReader) bytes() []byte {
+	return lr.line
+}
+
+// skimmed returns the top-level fields of a line that was too long to
+// decode: its ids, type and timestamp, without the message. It reports
+// false if the line was not too long or no such fields were found.
+func (lr *lineReader) skimmed() (jsonLine, bool) {
+	var line jsonLine
+	if lr.skim == nil || len(lr.skim.fields) == 0 {
+		return line, false
+	}
+	data, err := json.Marshal(lr.skim.fields)
+	if err != nil || json.Unmarshal(data, &line) != nil {
+		return line, false
+	}
+	line.skimmed = true
+	return line, true
+}
+
+// skimFields are the top-level fields a skimmer keeps: enough to place a
+// line in the conversation tree and count it
+var skimFields = map[string]bool{
+	"type": true, "uuid": true, "parentUuid": true, "logicalParentUuid": true,
+	"isSidechain": true, "sessionId": true, "timestamp": true, "cwd": true, "gitBranch": true,
+}
+
+// maxSkimValue is the longest field value a skimmer keeps
+const maxSkimValue = 4096
+
+// skimmer picks the scalar top-level fields in skimFields out of a JSON
+// object as it streams past, without holding the rest of it. Claude Code
+// writes uuid after the message, so a prefix of the line isn't enough.
+type skimmer struct {
+	depth    int
+	inString bool
+	escaped  bool
+	inKey    bool // reading a top-level key
+	atValue  bool // a top-level value comes next
+	capture  bool // reading a top-level value in skimFields
+	literal  bool // the value being read is a number, boolean or null
+	key      []byte
+	value    []byte
+	fields   map[string]json.RawMessage
+}
+
+func newSkimmer() *skimmer {
+	return &skimmer{fields: make(map[string]json.RawMessage)}
+}
+
+func (sk *skimmer) feed(chunk []byte) {
+	for _, c := range chunk {
+		if sk.inString {
+			sk.stringByte(c)
+			continue
+		}
+		if sk.capture && sk.literal {
+			if c != ',' && c != '}' && c != ']' && !isSpace(c) {
+				sk.appendValue(c)
+				continue
+			}
+			sk.finish()
+		}
+		switch c {
+		case '"':
+			sk.inString = true
+			if sk.depth == 1 && sk.atValue {
+				sk.atValue = false
+				sk.startValue(c, false)
+			} else if sk.depth == 1 {
+				sk.inKey = true
+				sk.key = sk.key[:0]
+			}
+		case '{', '[':
+			if sk.depth == 1 {
+				sk.atValue = false
+			}
+			sk.depth++
+		case '}', ']':
+			sk.depth--
+		case ':':
+			if sk.depth == 1 {
+				sk.atValue = true
+			}
+		case ',':
+		default:
+			if sk.depth == 1 && sk.atValue && !isSpace(c) {
+				sk.atValue = false
+				sk.startValue(c, true)
+			}
+		}
+	}
+}
+
+// stringByte handles a byte inside a string, which ends at an unescaped quote
+func (sk *skimmer) stringByte(c byte) {
+	closing := c == '"' && !sk.escaped
+	sk.escaped = !sk.escaped && c == '\\'
+	if sk.capture {
+		sk.appendValue(c)
+	} else if sk.inKey && !closing {
+		sk.key = append(sk.key, c)
+	}
+	if closing {
+		sk.inString = false
+		sk.inKey = false
+		if sk.capture {
+			sk.finish()
+		}
+	}
+}
+
+func (sk *skimmer) startValue(c byte, literal bool) {
+	if skimFields[string(sk.key)] {
+		sk.capture = true
+		sk.literal = literal
+		sk.value = append(sk.value[:0], c)
+	}
+}
+
+func (sk *skimmer) appendValue(c byte) {
+	if len(sk.value) >= maxSkimValue {
+		sk.capture = false
+		return
+	}
+	sk.value = append(sk.value, c)
+}
+
+func (sk *skimmer) finish() {
+	sk.fields[string(sk.key)] = bytes.Clone(sk.value)
+	sk.capture = false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// addWarning records a problem found while parsing, keeping the first few
+func (s *Session) addWarning(format string, args ...any) {
+	switch {
+	case len(s.Warnings) < maxWarnings:
+		s.Warnings = append(s.Warnings, fmt.Sprintf(format, args...))
+	case len(s.Warnings) == maxWarnings:
+		s.Warnings = append(s.Warnings, "more warnings not shown")
+	}
+}
+
+// formatSize renders a byte count in MiB for warnings
+func formatSize(n int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
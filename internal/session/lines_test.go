@@ -0,0 +1,119 @@
+package session
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkimmer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "strings and literals",
+			input: `{"type":"user","uuid":"u1","isSidechain":true,"parentUuid":null}`,
+			want:  map[string]string{"type": `"user"`, "uuid": `"u1"`, "isSidechain": "true", "parentUuid": "null"},
+		},
+		{
+			name:  "whitespace",
+			input: "{ \"uuid\" : \"u1\" ,\n\t\"isSidechain\" : false }",
+			want:  map[string]string{"uuid": `"u1"`, "isSidechain": "false"},
+		},
+		{
+			name:  "nested fields are ignored",
+			input: `{"message":{"uuid":"inner","type":"x","content":[{"uuid":"deeper"}]},"uuid":"outer"}`,
+			want:  map[string]string{"uuid": `"outer"`},
+		},
+		{
+			name:  "brackets and quotes in strings",
+			input: `{"content":["}",{"a":"]"},"\"uuid\":\"fake\\"],"text":"{\"type\":1","type":"user"}`,
+			want:  map[string]string{"type": `"user"`},
+		},
+		{
+			name:  "escapes in values",
+			input: `{"cwd":"C:\\dir\\\"q\"","uuid":"u\u00e9"}`,
+			want:  map[string]string{"cwd": `"C:\\dir\\\"q\""`, "uuid": `"u\u00e9"`},
+		},
+		{
+			name:  "literal last",
+			input: `{"uuid":"u1","isSidechain":true}`,
+			want:  map[string]string{"uuid": `"u1"`, "isSidechain": "true"},
+		},
+		{
+			name:  "values over maxSkimValue are dropped",
+			input: `{"uuid":"` + strings.Repeat("x", maxSkimValue) + `","type":"user"}`,
+			want:  map[string]string{"type": `"user"`},
+		},
+		{
+			name:  "other fields",
+			input: `{"text":"hi","count":3,"leafUuid":"l"}`,
+			want:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The line arrives in chunks of any size
+			for _, size := range []int{1, 3, len(tt.input)} {
+				sk := newSkimmer()
+				for i := 0; i < len(tt.input); i += size {
+					sk.feed([]byte(tt.input[i:min(i+size, len(tt.input))]))
+				}
+				got := make(map[string]string)
+				for k, v := range sk.fields {
+					got[k] = string(v)
+				}
+				if !maps.Equal(got, tt.want) {
+					t.Errorf("in chunks of %d: got %v, want %v", size, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// writeOversized writes a session whose second user line, a tool result,
+// is over maxLineSize: u1 → a1 → u2 (too long) → a2
+func writeOversized(t *testing.T) string {
+	t.Helper()
+	big := marshalLine(map[string]any{
+		"type":       "user",
+		"uuid":       "u2",
+		"parentUuid": "a1",
+		"sessionId":  "s",
+		"timestamp":  timestamp(2),
+		"message": map[string]any{
+			"role":    "user",
+			"content": []map[string]any{{"type": "tool_result", "tool_use_id": "t1", "content": strings.Repeat(`"x"\`, maxLineSize/4)}},
+		},
+	})
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	data := message("user", "u1", "", "first prompt", 0) +
+		response("a1", "u1", "msg_1", 1) +
+		big +
+		response("a2", "u2", "msg_2", 3)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFileOversizedLine(t *testing.T) {
+	s, err := ParseFile(writeOversized(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Branches != 1 || s.ActiveLeaf != "a2" {
+		t.Errorf("branches %d, active leaf %q; want 1, a2", s.Branches, s.ActiveLeaf)
+	}
+	if s.UserMsgCount != 1 || s.AsstMsgCount != 2 {
+		t.Errorf("%d user and %d assistant messages; want 1 and 2", s.UserMsgCount, s.AsstMsgCount)
+	}
+	if len(s.Warnings) != 1 {
+		t.Errorf("warnings %q; want one for the long line", s.Warnings)
+	}
+}
@@ -1,11 +1,15 @@
 package session
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 // jsonLine represents a single line in the JSONL file
@@ -14,75 +18,457 @@ type jsonLine struct {
 	Cwd     string `json:"cwd"`
 	Summary string `json:"summary"`
 	Message struct {
-		Content string `json:"content"`
+		ID      string         `json:"id"`
+		Model   string         `json:"model"`
+		Content messageContent `json:"content"`
+		Usage   *apiUsage      `json:"usage"`
 	} `json:"message"`
-	GitBranch string `json:"gitBranch"`
+	GitBranch   string `json:"gitBranch"`
+	Timestamp   string `json:"timestamp"`
+	IsSidechain bool   `json:"isSidechain"`
+	SessionID   string `json:"sessionId"`
+	UUID        string `json:"uuid"`
+	ParentUUID  string `json:"parentUuid"`
+	LeafUUID    string `json:"leafUuid"` // on summary lines: the last message summarized
+
+	// On compact_boundary lines, which have no parentUuid: the last message
+	// before the conversation was compacted
+	LogicalParentUUID string `json:"logicalParentUuid"`
+
+	skimmed bool // too long to decode; only the fields a skimmer keeps are set
+}
+
+// isMessage reports whether the line is a user or assistant message
+func (l jsonLine) isMessage() bool {
+	return l.Type == "user" || l.Type == "assistant"
+}
+
+// parent returns the line the conversation continues from. Compacting a
+// conversation writes a boundary line whose parentUuid is null; following
+// its logicalParentUuid keeps the messages before it on the same branch
+// instead of starting a new root.
+func (l jsonLine) parent() string {
+	if l.ParentUUID == "" {
+		return l.LogicalParentUUID
+	}
+	return l.ParentUUID
+}
+
+// apiUsage is message.usage on assistant lines
+type apiUsage struct {
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_input_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_input_tokens"`
+}
+
+// contentBlock is a single entry in a structured message content array
+type contentBlock struct {
+	Type      string          `json:"type"` // "text", "thinking", "tool_use", "tool_result", "image"
+	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"` // tool_result payload: string or blocks
+	IsError   bool            `json:"is_error"`
+}
+
+// messageContent holds message.content, which is either a plain string
+// or an array of content blocks. A plain string becomes a single text block.
+type messageContent struct {
+	Blocks []contentBlock
+}
+
+// UnmarshalJSON accepts both the string and the block-array form
+func (c *messageContent) UnmarshalJSON(data []byte) error {
+	c.Blocks = nil
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if text != "" {
+			c.Blocks = []contentBlock{{Type: "text", Text: text}}
+		}
+		return nil
+	}
+	return json.Unmarshal(data, &c.Blocks)
+}
+
+// Text joins the text blocks, ignoring tool calls, results and images
+func (c messageContent) Text() string {
+	var parts []string
+	for _, b := range c.Blocks {
+		if b.Type == "text" && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// count returns the number of blocks of the given type
+func (c messageContent) count(blockType string) int {
+	n := 0
+	for _, b := range c.Blocks {
+		if b.Type == blockType {
+			n++
+		}
+	}
+	return n
 }
 
 // ParseFile extracts session data from a JSONL file
 func ParseFile(path string) (Session, error) {
+	sess, _, err := ParseFileFrom(path, nil)
+	return sess, err
+}
+
+// ParseFileFrom is ParseFile for a file parsed before: when the file has
+// only grown since cp was taken, just the appended lines are read. It
+// returns a checkpoint for the next call, which is nil for small files and
+// for files that don't end in a complete line. cp itself isn't modified.
+func ParseFileFrom(path string, cp *Checkpoint) (Session, *Checkpoint, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return Session{}, err
+		return Session{}, nil, err
 	}
 	defer f.Close()
 
 	info, err := f.Stat()
 	if err != nil {
-		return Session{}, err
+		return Session{}, nil, err
+	}
+
+	if cp.resumable(f, info.Size()) {
+		cp = cp.clone()
+	} else {
+		cp = newCheckpoint(path)
+	}
+	cp.Base.ModTime = info.ModTime()
+	cp.Base.Size = info.Size()
+	if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+		return Session{}, nil, err
 	}
 
-	sess := Session{
-		ID:       strings.TrimSuffix(filepath.Base(path), ".jsonl"),
-		FilePath: path,
-		ModTime:  info.ModTime(),
+	// Track where each line ends, and whether the last one was complete or
+	// is still being written
+	offset, complete := cp.Offset, true
+	agent := isAgentFile(path)
+	lr := newLineReader(f)
+	for lr.next() {
+		offset += lr.size
+		complete = lr.terminated
+		cp.LineCount++
+		if lr.tooLong {
+			// Keep the line's place in the conversation even without its
+			// content, or the messages after it would start a new branch
+			line, ok := lr.skimmed()
+			if !ok {
+				cp.Base.addWarning("line %d: %s, over the %s limit; skipped", cp.LineCount, formatSize(lr.size), formatSize(maxLineSize))
+				continue
+			}
+			cp.Base.addWarning("line %d: %s, over the %s limit; content skipped", cp.LineCount, formatSize(lr.size), formatSize(maxLineSize))
+			cp.add(line, agent)
+			continue
+		}
+
+		var line jsonLine
+		if err := json.Unmarshal(lr.bytes(), &line); err != nil {
+			// Blank lines are harmless, and an unfinished last line is
+			// still being written
+			if lr.terminated && len(bytes.TrimSpace(lr.bytes())) > 0 {
+				cp.Base.addWarning("line %d: %v", cp.LineCount, err)
+			}
+			continue
+		}
+		cp.add(line, agent)
+	}
+	cp.Offset = offset
+	if lr.err != nil {
+		cp.Base.addWarning("read error after line %d: %v", cp.LineCount, lr.err)
 	}
 
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	sess := cp.session()
+	if lr.err != nil || !complete || cp.Offset < checkpointMinSize || cp.seal(f) != nil {
+		return sess, nil, nil
+	}
+	return sess, cp, nil
+}
 
-	var firstUserMsg string
+// ExtractText returns the text of every user and assistant message in a
+// JSONL file, one message per line. Tool calls, results and images are skipped.
+func ExtractText(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
 
-	for scanner.Scan() {
+	var b strings.Builder
+	lr := newLineReader(f)
+	for lr.next() {
 		var line jsonLine
-		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+		if lr.tooLong || json.Unmarshal(lr.bytes(), &line) != nil {
+			continue
+		}
+		if line.Type != "user" && line.Type != "assistant" {
 			continue
 		}
+		if text := line.Message.Content.Text(); text != "" {
+			b.WriteString(text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), lr.err
+}
 
-		sess.processLine(line, &firstUserMsg)
+// add records a line. Totals that don't depend on the active branch go
+// straight into Base; the rest is kept as a lineRecord for session.
+func (cp *Checkpoint) add(line jsonLine, agent bool) {
+	s := &cp.Base
+	if agent && s.ParentID == "" {
+		s.ParentID = line.SessionID
+	}
+	if !agent && s.ForeignSessionID == "" && line.SessionID != "" && line.SessionID != s.ID {
+		s.ForeignSessionID = line.SessionID
+	}
+
+	var day string
+	if t, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+		t = t.Local()
+		day = t.Format(DayLayout)
+		if s.StartedAt.IsZero() || t.Before(s.StartedAt) {
+			s.StartedAt = t
+		}
+		if t.After(s.LastActivityAt) {
+			s.LastActivityAt = t
+		}
+	}
+
+	// Every branch cost tokens, whether or not it is still active
+	if line.Type == "assistant" {
+		s.addUsage(line, day, cp.UsageIDs)
+	}
+
+	// Sidechain lines inside a session file belong to a subagent run. Like
+	// abandoned branches, they cost tokens but aren't part of the
+	// conversation.
+	if line.IsSidechain && !agent {
+		return
+	}
+
+	r := lineRecord{Type: line.Type, UUID: line.UUID, Parent: line.parent(), GitBranch: line.GitBranch, Day: day, Skimmed: line.skimmed}
+	switch line.Type {
+	case "user":
+		r.Cwd = line.Cwd
+		r.Results = line.Message.Content.count("tool_result")
+		r.Text = line.Message.Content.Text()
+		if !agent {
+			// Only the summary is taken from it
+			r.Text = truncate(r.Text, 60)
+		}
+	case "assistant":
+		r.Model = line.Message.Model
+		r.Calls = line.Message.Content.count("tool_use")
+	case "summary":
+		if line.Summary == "" {
+			break
+		}
+		// A summary names the message it summarizes, which may be in another
+		// file; ResolveSummaries assigns it once every file is parsed
+		if line.LeafUUID != "" {
+			if s.LeafSummaries == nil {
+				s.LeafSummaries = make(map[string]string)
+			}
+			if _, seen := s.LeafSummaries[line.LeafUUID]; seen {
+				s.SummaryLeaves = slices.DeleteFunc(s.SummaryLeaves, func(leaf string) bool { return leaf == line.LeafUUID })
+			}
+			s.LeafSummaries[line.LeafUUID] = line.Summary
+			s.SummaryLeaves = append(s.SummaryLeaves, line.LeafUUID)
+		} else {
+			s.Summary = line.Summary
+		}
+	}
+	if r.isMessage() || r.UUID != "" || r.GitBranch != "" {
+		cp.Lines = append(cp.Lines, r)
+	}
+}
+
+// session returns the parsed session: the totals in Base plus the lines on
+// the active branch. Which branch is active is only known once the last
+// line has been seen.
+func (cp *Checkpoint) session() Session {
+	sess := cp.Base
+	sess.ModelUsage = maps.Clone(sess.ModelUsage)
+	sess.LeafSummaries = maps.Clone(sess.LeafSummaries)
+	sess.Days = maps.Clone(sess.Days)
+
+	t := newTree()
+	for _, r := range cp.Lines {
+		t.add(r.UUID, r.Parent, r.isMessage())
+	}
+	if len(t.order) > 0 {
+		sess.RootUUID = t.order[0]
+		sess.ExternalParent = t.externalParent()
+	}
+
+	var active map[string]bool
+	if leaves := t.leaves(); len(leaves) > 0 {
+		sess.ActiveLeaf = leaves[len(leaves)-1]
+		sess.Branches = len(leaves)
+		sess.LeafUUIDs = leaves
+		active = t.path(sess.ActiveLeaf)
+	}
+
+	var firstUserMsg string
+	for _, r := range cp.Lines {
+		if active == nil || !r.isMessage() || r.UUID == "" || active[r.UUID] {
+			sess.count(r, &firstUserMsg)
+		}
+	}
+
+	// A summary of a message in this file describes it even if that message
+	// has since been replied to, which ResolveSummaries, matching only
+	// branch tips, wouldn't find
+	if sess.Summary == "" {
+		for k := len(sess.SummaryLeaves) - 1; k >= 0; k-- {
+			if leaf := sess.SummaryLeaves[k]; t.has(leaf) {
+				sess.Summary = sess.LeafSummaries[leaf]
+				break
+			}
+		}
 	}
 
 	sess.finalizeSummary(firstUserMsg)
-	return sess, nil
+	if isAgentFile(sess.FilePath) {
+		sess.Prompt = firstUserMsg
+	}
+	if sess.LastActivityAt.IsZero() {
+		sess.StartedAt = sess.ModTime
+		sess.LastActivityAt = sess.ModTime
+	}
+	return sess
 }
 
-func (s *Session) processLine(line jsonLine, firstUserMsg *string) {
-	switch line.Type {
+// count adds a line on the active branch to the message counts
+func (s *Session) count(r lineRecord, firstUserMsg *string) {
+	switch r.Type {
 	case "user":
-		s.UserMsgCount++
-		if s.ProjectPath == "" && line.Cwd != "" {
-			s.ProjectPath = line.Cwd
+		if s.ProjectPath == "" && r.Cwd != "" {
+			s.ProjectPath = r.Cwd
 		}
-		if *firstUserMsg == "" && line.Message.Content != "" {
-			*firstUserMsg = line.Message.Content
+		// Tool results come back as user lines; they aren't user turns.
+		// Nor are lines too long to read, which are nearly always tool
+		// results, though they still count as activity.
+		s.ToolResultCount += r.Results
+		if r.Skimmed {
+			s.addActivity(r.Day, 1, Usage{})
+			break
+		}
+		if r.Results > 0 && r.Text == "" {
+			break
+		}
+		s.UserMsgCount++
+		s.addActivity(r.Day, 1, Usage{})
+		if *firstUserMsg == "" && r.Text != "" {
+			*firstUserMsg = r.Text
 		}
 	case "assistant":
 		s.AsstMsgCount++
-	case "summary":
-		if line.Summary != "" {
-			s.Summary = line.Summary
+		s.addActivity(r.Day, 1, Usage{})
+		s.addModel(r.Model)
+		s.ToolCallCount += r.Calls
+	}
+
+	if r.GitBranch != "" && s.GitBranch == "" {
+		s.GitBranch = r.GitBranch
+	}
+}
+
+// addUsage adds an assistant line's token usage to the session totals and
+// to the day it was sent
+func (s *Session) addUsage(line jsonLine, day string, seen map[string]bool) {
+	u := line.Message.Usage
+	if u == nil {
+		return
+	}
+	if id := line.Message.ID; id != "" {
+		// Claude Code writes one API response as several lines, one per
+		// content block, each repeating the response's usage
+		if seen[id] {
+			return
 		}
+		seen[id] = true
 	}
 
-	if line.GitBranch != "" && s.GitBranch == "" {
-		s.GitBranch = line.GitBranch
+	model := line.Message.Model
+	if model == "" {
+		model = "unknown"
+	}
+	usage := Usage{
+		InputTokens:         u.InputTokens,
+		OutputTokens:        u.OutputTokens,
+		CacheReadTokens:     u.CacheReadTokens,
+		CacheCreationTokens: u.CacheCreationTokens,
+	}
+	s.addModelUsage(model, usage)
+	s.addActivity(day, 0, usage)
+}
+
+// addActivity records messages and token usage on a day. Lines without a
+// timestamp have no day and aren't recorded.
+func (s *Session) addActivity(day string, messages int, usage Usage) {
+	if day == "" {
+		return
+	}
+	if s.Days == nil {
+		s.Days = make(map[string]DayActivity)
+	}
+	a := s.Days[day]
+	a.Messages += messages
+	a.Usage.Add(usage)
+	s.Days[day] = a
+}
+
+func (s *Session) addModelUsage(model string, usage Usage) {
+	s.Usage.Add(usage)
+	if s.ModelUsage == nil {
+		s.ModelUsage = make(map[string]Usage)
+	}
+	total := s.ModelUsage[model]
+	total.Add(usage)
+	s.ModelUsage[model] = total
+}
+
+// isAgentFile reports whether path is a subagent transcript
+func isAgentFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "agent-")
+}
+
+// addModel records a model the first time it is seen. Placeholders such as
+// <synthetic>, used for locally generated messages, aren't models.
+func (s *Session) addModel(model string) {
+	if model == "" || strings.HasPrefix(model, "<") {
+		return
+	}
+	for _, m := range s.Models {
+		if m == model {
+			return
+		}
 	}
+	s.Models = append(s.Models, model)
 }
 
 func (s *Session) finalizeSummary(firstUserMsg string) {
 	if s.Summary != "" {
 		return
 	}
+	s.PromptSummary = true
 	if firstUserMsg != "" {
 		s.Summary = truncate(firstUserMsg, 60)
 		return
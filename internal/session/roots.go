@@ -0,0 +1,59 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Root is a Claude config directory, whose projects directory holds
+// sessions. Claude Code uses ~/.claude unless CLAUDE_CONFIG_DIR is set, and
+// some people keep several (work and personal, say).
+type Root struct {
+	Name string // label for its sessions; defaults to the directory name
+	Dir  string
+}
+
+// NewRoot returns a root for dir, named after the directory if name is empty
+func NewRoot(name, dir string) Root {
+	dir = filepath.Clean(dir)
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Base(dir), ".")
+	}
+	return Root{Name: name, Dir: dir}
+}
+
+// DefaultRoot is the root Claude Code itself uses: $CLAUDE_CONFIG_DIR, or
+// ~/.claude when that isn't set
+func DefaultRoot() Root {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return NewRoot("", dir)
+	}
+	return NewRoot("", homeConfigDir())
+}
+
+// ProjectsDir is where the root's sessions are
+func (r Root) ProjectsDir() string {
+	return filepath.Join(r.Dir, "projects")
+}
+
+// label records which root a session was found in
+func (r Root) label(s *Session) {
+	s.Root = r.Name
+	s.ConfigDir = r.configDir()
+}
+
+// configDir returns the CLAUDE_CONFIG_DIR to resume the root's sessions
+// with. It is empty for ~/.claude, since claude only finds ~/.claude.json
+// (login and settings) when the variable isn't set.
+func (r Root) configDir() string {
+	if r.Dir == homeConfigDir() {
+		return ""
+	}
+	return r.Dir
+}
+
+func homeConfigDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claude")
+}
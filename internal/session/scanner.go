@@ -1,115 +1,209 @@
 package session
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 )
 
-const claudeProjectsDir = ".claude/projects"
+// progressInterval is the least time between progress reports while files
+// are being parsed, since each report links every session found so far
+const progressInterval = 100 * time.Millisecond
 
 // SessionCache is an interface for caching sessions
 type SessionCache interface {
 	Get(path string, mtime time.Time) (Session, bool)
-	Set(path string, mtime time.Time, sess Session)
+	Checkpoint(path string) *Checkpoint
+	Set(path string, mtime time.Time, sess Session, cp *Checkpoint)
 	Prune(validPaths map[string]bool)
 }
 
+// Progress is a snapshot of a scan: the sessions found so far, linked as if
+// they were all there are, and how many of the files that weren't cached
+// have been parsed
+type Progress struct {
+	Sessions []Session
+	Parsed   int
+	Total    int
+}
+
+// Result is one session file from a Scan
+type Result struct {
+	Path    string
+	Session Session // unlinked: subagent runs are still separate sessions
+	Cached  bool    // served from the cache rather than parsed
+	Err     error   // the file couldn't be read
+}
+
+// Scan is a scan in progress, started by Scanner.Stream
+type Scan struct {
+	// Results has one result per session file. It is closed once every
+	// file is done, or soon after the scan's context is cancelled. It is
+	// buffered for every file, so workers never wait on a slow reader and
+	// a reader can stop early without leaking them.
+	Results <-chan Result
+	Cached  int // results served from the cache, which come first
+	ToParse int // files being parsed, which follow as each is done
+}
+
 // Scanner finds and parses Claude sessions
 type Scanner struct {
-	baseDir string
+	roots []Root
+}
+
+// NewScanner creates a scanner for the sessions in roots, or in
+// DefaultRoot if none are given
+func NewScanner(roots ...Root) *Scanner {
+	if len(roots) == 0 {
+		roots = []Root{DefaultRoot()}
+	}
+	s := &Scanner{}
+	seen := make(map[string]bool)
+	for _, r := range roots {
+		if !seen[r.Dir] {
+			seen[r.Dir] = true
+			s.roots = append(s.roots, r)
+		}
+	}
+	return s
 }
 
-// NewScanner creates a scanner for the default Claude projects directory
-func NewScanner() *Scanner {
-	home, _ := os.UserHomeDir()
-	return &Scanner{baseDir: filepath.Join(home, claudeProjectsDir)}
+// Dirs returns the directories the scanner looks for sessions in
+func (s *Scanner) Dirs() []string {
+	dirs := make([]string, len(s.roots))
+	for i, r := range s.roots {
+		dirs[i] = r.ProjectsDir()
+	}
+	return dirs
 }
 
 // ScanAll finds all sessions with parallel processing (no caching)
 func (s *Scanner) ScanAll() ([]Session, error) {
-	files, err := s.findSessionFiles()
+	scan, err := s.Stream(context.Background(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.parseFilesParallel(files, nil), nil
+	var sessions []Session
+	for r := range scan.Results {
+		if r.Err == nil {
+			sessions = append(sessions, r.Session)
+		}
+	}
+	return link(sessions), nil
 }
 
 // ScanAllCached finds sessions using cache for unchanged files
 func (s *Scanner) ScanAllCached(cache SessionCache) ([]Session, error) {
-	files, err := s.findSessionFilesWithInfo()
+	return s.ScanAllCachedProgress(context.Background(), cache, nil)
+}
+
+// ScanAllCachedProgress is ScanAllCached, calling report as it goes: first
+// with the cached sessions before any file is parsed, then every so often
+// while the rest are parsed, and last with every session. When everything
+// is cached, that is a single call. report may be nil. If ctx is cancelled
+// the scan stops early with ctx's error; what was parsed is still cached.
+func (s *Scanner) ScanAllCachedProgress(ctx context.Context, cache SessionCache, report func(Progress)) ([]Session, error) {
+	scan, err := s.Stream(ctx, cache)
 	if err != nil {
 		return nil, err
 	}
-
-	validPaths := make(map[string]bool, len(files))
-	for _, f := range files {
-		validPaths[f.path] = true
+	if report == nil {
+		report = func(Progress) {}
 	}
 
-	sessions := s.parseFilesWithCache(files, cache)
-	cache.Prune(validPaths)
-	return sessions, nil
-}
-
-type fileInfo struct {
-	path    string
-	modTime time.Time
-}
-
-func (s *Scanner) findSessionFiles() ([]string, error) {
-	var files []string
-
-	err := filepath.WalkDir(s.baseDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
+	var sessions []Session
+	cached, parsed := 0, 0
+	last := time.Now()
+	if scan.Cached == 0 && scan.ToParse > 0 {
+		report(Progress{Total: scan.ToParse})
+	}
+	for r := range scan.Results {
+		if r.Err == nil {
+			sessions = append(sessions, r.Session)
 		}
-		if !strings.HasSuffix(path, ".jsonl") || strings.HasPrefix(d.Name(), "agent-") {
-			return nil
+		if r.Cached {
+			// Cached results come first; report them once they are all in
+			if cached++; cached == scan.Cached && scan.ToParse > 0 {
+				report(Progress{Sessions: link(sessions), Total: scan.ToParse})
+			}
+			continue
 		}
-		files = append(files, path)
-		return nil
-	})
+		parsed++
+		if parsed < scan.ToParse && time.Since(last) >= progressInterval {
+			report(Progress{Sessions: link(sessions), Parsed: parsed, Total: scan.ToParse})
+			last = time.Now()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	return files, err
+	linked := link(sessions)
+	report(Progress{Sessions: linked, Parsed: parsed, Total: scan.ToParse})
+	return linked, nil
 }
 
-func (s *Scanner) findSessionFilesWithInfo() ([]fileInfo, error) {
-	var files []fileInfo
+// Stream starts a scan and returns as soon as the session files have been
+// listed. Files whose cache entry is current are served from the cache
+// (cache may be nil); the rest are parsed by a pool of workers, one per
+// CPU, and stored in the cache. Cancelling ctx stops the scan.
+func (s *Scanner) Stream(ctx context.Context, cache SessionCache) (*Scan, error) {
+	files, err := s.findSessionFiles()
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.WalkDir(s.baseDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(path, ".jsonl") || strings.HasPrefix(d.Name(), "agent-") {
-			return nil
-		}
-		info, err := d.Info()
-		if err != nil {
-			return nil
+	results := make(chan Result, len(files))
+	scan := &Scan{Results: results}
+
+	var stale []fileInfo
+	if cache == nil {
+		stale = files
+	} else {
+		validPaths := make(map[string]bool, len(files))
+		for _, f := range files {
+			validPaths[f.path] = true
+			if cached, ok := cache.Get(f.path, f.modTime); ok {
+				f.root.label(&cached)
+				results <- Result{Path: f.path, Session: cached, Cached: true}
+				scan.Cached++
+			} else {
+				stale = append(stale, f)
+			}
 		}
-		files = append(files, fileInfo{path: path, modTime: info.ModTime()})
-		return nil
-	})
+		cache.Prune(validPaths)
+	}
+	scan.ToParse = len(stale)
 
-	return files, err
-}
+	jobs := make(chan fileInfo)
+	go func() {
+		defer close(jobs)
+		for _, f := range stale {
+			select {
+			case jobs <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
-func (s *Scanner) parseFilesParallel(files []string, _ SessionCache) []Session {
 	var wg sync.WaitGroup
-	results := make(chan Session, len(files))
-
-	for _, f := range files {
+	for i := 0; i < min(runtime.GOMAXPROCS(0), len(stale)); i++ {
 		wg.Add(1)
-		go func(path string) {
+		go func() {
 			defer wg.Done()
-			if sess, err := ParseFile(path); err == nil {
-				results <- sess
+			for f := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				results <- parseFile(f, cache)
 			}
-		}(f)
+		}()
 	}
 
 	go func() {
@@ -117,45 +211,66 @@ func (s *Scanner) parseFilesParallel(files []string, _ SessionCache) []Session {
 		close(results)
 	}()
 
-	return collectResults(results)
+	return scan, nil
 }
 
-func (s *Scanner) parseFilesWithCache(files []fileInfo, cache SessionCache) []Session {
-	var wg sync.WaitGroup
-	results := make(chan Session, len(files))
-
-	for _, f := range files {
-		// Check cache first
-		if cached, ok := cache.Get(f.path, f.modTime); ok {
-			results <- cached
-			continue
+// parseFile parses a session file, only its new lines if it has grown
+// since it was cached
+func parseFile(f fileInfo, cache SessionCache) Result {
+	var sess Session
+	var err error
+	if cache == nil {
+		sess, err = ParseFile(f.path)
+	} else {
+		var cp *Checkpoint
+		sess, cp, err = ParseFileFrom(f.path, cache.Checkpoint(f.path))
+		if err == nil {
+			cache.Set(f.path, f.modTime, sess, cp)
 		}
-
-		// Parse in parallel
-		wg.Add(1)
-		go func(fi fileInfo) {
-			defer wg.Done()
-			sess, err := ParseFile(fi.path)
-			if err != nil {
-				return
-			}
-			cache.Set(fi.path, fi.modTime, sess)
-			results <- sess
-		}(f)
 	}
+	f.root.label(&sess)
+	return Result{Path: f.path, Session: sess, Err: err}
+}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// link connects sessions across files: subagent runs to the sessions that
+// started them, summaries to the sessions they describe, and resumed
+// conversations to the sessions they continue. The sessions passed in are
+// left as they were.
+func link(sessions []Session) []Session {
+	linked := LinkSubagents(sessions)
+	ResolveSummaries(linked)
+	LinkContinuations(linked)
+	return linked
+}
 
-	return collectResults(results)
+type fileInfo struct {
+	path    string
+	modTime time.Time
+	root    Root
 }
 
-func collectResults(results chan Session) []Session {
-	var sessions []Session
-	for sess := range results {
-		sessions = append(sessions, sess)
+func (s *Scanner) findSessionFiles() ([]fileInfo, error) {
+	var files []fileInfo
+
+	for _, root := range s.roots {
+		err := filepath.WalkDir(root.ProjectsDir(), func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if !strings.HasSuffix(path, ".jsonl") {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files = append(files, fileInfo{path: path, modTime: info.ModTime(), root: root})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	return sessions
+
+	return files, nil
 }
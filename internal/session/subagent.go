@@ -0,0 +1,104 @@
+package session
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LinkSubagents attaches subagent transcripts to the sessions that started
+// them and returns the sessions without the subagent entries. A subagent's
+// token usage is added to its parent, since the parent paid for it.
+// Subagents whose parent isn't among sessions are dropped. The sessions
+// passed in are left as they were.
+func LinkSubagents(sessions []Session) []Session {
+	var out []Session
+	var agents []Session
+	for _, s := range sessions {
+		if s.ParentID != "" || isAgentFile(s.FilePath) {
+			agents = append(agents, s)
+		} else {
+			out = append(out, s)
+		}
+	}
+	if len(agents) == 0 {
+		return out
+	}
+
+	parents := make(map[string]int, len(out))
+	for i, s := range out {
+		parents[s.ID] = i
+	}
+
+	copied := make(map[int]bool)
+	for _, a := range agents {
+		i, ok := parents[a.ParentID]
+		if !ok {
+			continue
+		}
+		p := &out[i]
+		if !copied[i] {
+			// The parent's map and slices are shared with the caller's
+			// session (and its cache entry), which may be linked again
+			copied[i] = true
+			p.ModelUsage = maps.Clone(p.ModelUsage)
+			p.Days = maps.Clone(p.Days)
+			p.Models = slices.Clip(p.Models)
+			p.Subagents = slices.Clip(p.Subagents)
+		}
+		p.Subagents = append(p.Subagents, Subagent{
+			ID:        strings.TrimPrefix(a.ID, "agent-"),
+			FilePath:  a.FilePath,
+			Prompt:    a.Prompt,
+			StartedAt: a.StartedAt,
+			MsgCount:  a.UserMsgCount + a.AsstMsgCount,
+		})
+		for model, u := range a.ModelUsage {
+			p.addModelUsage(model, u)
+		}
+		for day, activity := range a.Days {
+			p.addActivity(day, 0, activity.Usage)
+		}
+		for _, model := range a.Models {
+			p.addModel(model)
+		}
+	}
+
+	for i := range out {
+		subs := out[i].Subagents
+		sort.Slice(subs, func(a, b int) bool {
+			return subs[a].StartedAt.Before(subs[b].StartedAt)
+		})
+	}
+	return out
+}
+
+// MatchSubagents pairs the Task tool calls in a transcript with the
+// subagent runs they started, keyed by tool call ID. Calls are matched by
+// prompt in order, so repeated prompts pair with successive runs.
+func MatchSubagents(messages []Message, subagents []Subagent) map[string]Subagent {
+	matched := make(map[string]Subagent)
+	used := make([]bool, len(subagents))
+
+	for _, msg := range messages {
+		for _, call := range msg.ToolCalls {
+			var input struct {
+				Prompt string `json:"prompt"`
+			}
+			if json.Unmarshal(call.Input, &input) != nil || input.Prompt == "" {
+				continue
+			}
+			prompt := strings.TrimSpace(input.Prompt)
+			for i, sub := range subagents {
+				if !used[i] && strings.TrimSpace(sub.Prompt) == prompt {
+					used[i] = true
+					matched[call.ID] = sub
+					break
+				}
+			}
+		}
+	}
+	return matched
+}
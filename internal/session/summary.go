@@ -0,0 +1,80 @@
+package session
+
+import "sort"
+
+// ResolveSummaries gives each session the summary written for one of its
+// leaf messages, wherever that summary line was found. Claude Code often
+// writes the summary of a conversation at the top of the file that
+// continues it, so a file holding a summary of another file's leaf is
+// recorded as continuing that session. Sessions without a resolved summary
+// keep the one from ParseFile (a summary line without leafUuid or of one of
+// their own messages), else take a summary line of theirs whose leaf is in
+// no scanned file, else keep their first prompt.
+func ResolveSummaries(sessions []Session) {
+	owner := make(map[string]int)
+	for i, s := range sessions {
+		for _, leaf := range s.LeafUUIDs {
+			owner[leaf] = i
+		}
+	}
+
+	// Visit files oldest first so that when several files summarize the
+	// same leaf, the newest summary wins
+	order := make([]int, len(sessions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sessions[order[a]].LastActivityAt.Before(sessions[order[b]].LastActivityAt)
+	})
+
+	resolved := make(map[string]string)
+	continues := make(map[int]int)
+	for _, j := range order {
+		for _, leaf := range sessions[j].SummaryLeaves {
+			summary := sessions[j].LeafSummaries[leaf]
+			i, ok := owner[leaf]
+			if !ok {
+				continue
+			}
+			resolved[leaf] = summary
+			// A file summarizing several others continues the latest of them.
+			// Like LinkContinuations, only link to earlier activity.
+			if i == j || !sessions[i].LastActivityAt.Before(sessions[j].LastActivityAt) {
+				continue
+			}
+			if prev, ok := continues[j]; !ok || sessions[i].LastActivityAt.After(sessions[prev].LastActivityAt) {
+				continues[j] = i
+			}
+		}
+	}
+	for j, i := range continues {
+		sessions[j].ContinuesFrom = sessions[i].ID
+	}
+
+	for i := range sessions {
+		s := &sessions[i]
+		if summary, ok := resolved[s.ActiveLeaf]; ok {
+			s.Summary = summary
+			continue
+		}
+		found := false
+		for k := len(s.LeafUUIDs) - 1; k >= 0 && !found; k-- {
+			if summary, ok := resolved[s.LeafUUIDs[k]]; ok {
+				s.Summary = summary
+				found = true
+			}
+		}
+		if found || !s.PromptSummary {
+			continue
+		}
+		// The file it summarizes may have been deleted or not scanned
+		for k := len(s.SummaryLeaves) - 1; k >= 0; k-- {
+			leaf := s.SummaryLeaves[k]
+			if _, ok := owner[leaf]; !ok {
+				s.Summary = s.LeafSummaries[leaf]
+				break
+			}
+		}
+	}
+}
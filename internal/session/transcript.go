@@ -0,0 +1,119 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Message is a single user or assistant line of a session transcript
+type Message struct {
+	UUID        string
+	ParentUUID  string // parent message, skipping non-message lines
+	Role        string // "user" or "assistant"
+	Text        string
+	Thinking    string
+	ToolCalls   []ToolCall
+	ToolResults []ToolResult
+}
+
+// ToolCall is a tool invocation made by the assistant
+type ToolCall struct {
+	ID    string
+	Name  string
+	Input json.RawMessage
+}
+
+// ToolResult is the output of a tool call, sent back on a user line
+type ToolResult struct {
+	ToolUseID string
+	Content   string
+	IsError   bool
+}
+
+// LoadTranscript reads the user and assistant messages of a session or
+// subagent file in file order, across every branch; use Thread or
+// ActiveThread to follow one. Sidechain lines inside a session file are
+// skipped; their subagent runs are loaded from their own files.
+func LoadTranscript(path string) ([]Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	agent := isAgentFile(path)
+	t := newTree()
+	var messages []Message
+	lr := newLineReader(f)
+	for lr.next() {
+		var line jsonLine
+		if lr.tooLong {
+			// Keep the line in the tree so the messages after it stay on
+			// the same thread
+			var ok bool
+			if line, ok = lr.skimmed(); !ok {
+				continue
+			}
+		} else if json.Unmarshal(lr.bytes(), &line) != nil {
+			continue
+		}
+		if line.IsSidechain && !agent {
+			continue
+		}
+		t.add(line.UUID, line.parent(), line.isMessage())
+		if !line.isMessage() {
+			continue
+		}
+		msg := newMessage(line.Type, line.Message.Content)
+		if line.skimmed {
+			msg.Text = fmt.Sprintf("(%s line, content skipped)", formatSize(lr.size))
+		}
+		msg.UUID = line.UUID
+		messages = append(messages, msg)
+	}
+
+	if lr.err != nil {
+		return nil, lr.err
+	}
+
+	for i := range messages {
+		messages[i].ParentUUID = t.parentOf(messages[i].UUID)
+	}
+	return messages, nil
+}
+
+func newMessage(role string, content messageContent) Message {
+	msg := Message{Role: role, Text: content.Text()}
+	for _, b := range content.Blocks {
+		switch b.Type {
+		case "thinking":
+			if msg.Thinking != "" {
+				msg.Thinking += "\n"
+			}
+			msg.Thinking += b.Thinking
+		case "tool_use":
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{ID: b.ID, Name: b.Name, Input: b.Input})
+		case "tool_result":
+			msg.ToolResults = append(msg.ToolResults, ToolResult{
+				ToolUseID: b.ToolUseID,
+				Content:   resultText(b.Content),
+				IsError:   b.IsError,
+			})
+		}
+	}
+	return msg
+}
+
+// resultText flattens a tool_result payload, which is either a string or
+// an array of content blocks
+func resultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var content messageContent
+	if json.Unmarshal(raw, &content) != nil {
+		return ""
+	}
+	return content.Text()
+}
@@ -0,0 +1,28 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadTranscriptOversizedLine(t *testing.T) {
+	messages, err := LoadTranscript(writeOversized(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaves := Leaves(messages); len(leaves) != 1 {
+		t.Errorf("leaves %q; want just a2", leaves)
+	}
+
+	thread := ActiveThread(messages)
+	var uuids []string
+	for _, m := range thread {
+		uuids = append(uuids, m.UUID)
+	}
+	if got := strings.Join(uuids, " "); got != "u1 a1 u2 a2" {
+		t.Fatalf("active thread %q; want u1 a1 u2 a2", got)
+	}
+	if !strings.Contains(thread[2].Text, "content skipped") {
+		t.Errorf("oversized line text %q; want a placeholder", thread[2].Text)
+	}
+}
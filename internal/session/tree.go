@@ -0,0 +1,139 @@
+package session
+
+// tree links the user and assistant messages of a session by uuid and
+// parentUuid. Rewinding or editing a message starts a new branch from an
+// earlier message, so the file holds every branch ever taken. Other lines
+// with a uuid (system notices and the like) are passed through: a message
+// whose parent is such a line is linked to that line's parent instead.
+type tree struct {
+	parent  map[string]string // message uuid -> parent message uuid
+	through map[string]string // non-message uuid -> its parent
+	order   []string          // message uuids in file order
+}
+
+func newTree() *tree {
+	return &tree{parent: make(map[string]string), through: make(map[string]string)}
+}
+
+// add records a line. Lines without a uuid are ignored.
+func (t *tree) add(uuid, parent string, message bool) {
+	if uuid == "" {
+		return
+	}
+	if !message {
+		t.through[uuid] = parent
+		return
+	}
+	if _, seen := t.parent[uuid]; !seen {
+		t.order = append(t.order, uuid)
+	}
+	t.parent[uuid] = parent
+}
+
+// has reports whether uuid is a line in the tree
+func (t *tree) has(uuid string) bool {
+	_, message := t.parent[uuid]
+	_, other := t.through[uuid]
+	return message || other
+}
+
+// parentOf returns a message's parent message, skipping pass-through lines
+func (t *tree) parentOf(uuid string) string {
+	p := t.parent[uuid]
+	for hops := 0; hops < len(t.through); hops++ {
+		next, ok := t.through[p]
+		if !ok {
+			break
+		}
+		p = next
+	}
+	return p
+}
+
+// leaves returns the tips of the branches in file order. The last one is
+// the active leaf, since it was written most recently.
+func (t *tree) leaves() []string {
+	hasChild := make(map[string]bool, len(t.order))
+	for _, uuid := range t.order {
+		hasChild[t.parentOf(uuid)] = true
+	}
+	var leaves []string
+	for _, uuid := range t.order {
+		if !hasChild[uuid] {
+			leaves = append(leaves, uuid)
+		}
+	}
+	return leaves
+}
+
+// externalParent returns the first parent reference to a line that isn't
+// in the tree, which happens when a file continues another one
+func (t *tree) externalParent() string {
+	for _, uuid := range t.order {
+		p := t.parentOf(uuid)
+		if p == "" {
+			continue
+		}
+		if t.has(p) {
+			continue
+		}
+		return p
+	}
+	return ""
+}
+
+// path returns the set of messages from the root to leaf
+func (t *tree) path(leaf string) map[string]bool {
+	on := make(map[string]bool)
+	for uuid := leaf; uuid != "" && !on[uuid]; uuid = t.parentOf(uuid) {
+		if _, ok := t.parent[uuid]; !ok {
+			break
+		}
+		on[uuid] = true
+	}
+	return on
+}
+
+// Leaves returns the leaf message of each conversation branch in a
+// transcript, in file order; the last is the active branch. Transcripts
+// without uuids have no leaves.
+func Leaves(messages []Message) []string {
+	return messageTree(messages).leaves()
+}
+
+// Thread returns the messages on the branch ending at leaf, in order.
+// With an empty leaf, or for transcripts without uuids, it returns
+// messages unchanged.
+func Thread(messages []Message, leaf string) []Message {
+	if leaf == "" {
+		return messages
+	}
+	on := messageTree(messages).path(leaf)
+	if len(on) == 0 {
+		return messages
+	}
+	var thread []Message
+	for _, msg := range messages {
+		if on[msg.UUID] {
+			thread = append(thread, msg)
+		}
+	}
+	return thread
+}
+
+// ActiveThread returns the messages on the most recently written branch
+func ActiveThread(messages []Message) []Message {
+	leaves := Leaves(messages)
+	if len(leaves) == 0 {
+		return messages
+	}
+	return Thread(messages, leaves[len(leaves)-1])
+}
+
+func messageTree(messages []Message) *tree {
+	t := newTree()
+	for _, msg := range messages {
+		t.add(msg.UUID, msg.ParentUUID, true)
+	}
+	return t
+}
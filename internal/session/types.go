@@ -4,12 +4,90 @@ import "time"
 
 // Session represents a Claude Code session
 type Session struct {
-	ID           string
-	ProjectPath  string
-	Summary      string
-	ModTime      time.Time
-	FilePath     string
-	GitBranch    string
-	UserMsgCount int
-	AsstMsgCount int
+	ID              string           `json:"id"`
+	ProjectPath     string           `json:"project_path"`
+	Summary         string           `json:"summary"`
+	ModTime         time.Time        `json:"mod_time"`
+	StartedAt       time.Time        `json:"started_at"`       // first message; ModTime if none are timestamped
+	LastActivityAt  time.Time        `json:"last_activity_at"` // last message; ModTime if none are timestamped
+	FilePath        string           `json:"file_path"`
+	Size            int64            `json:"size"`
+	GitBranch       string           `json:"git_branch"`
+	Models          []string         `json:"models"` // in order of first use
+	UserMsgCount    int              `json:"user_msg_count"`
+	AsstMsgCount    int              `json:"asst_msg_count"`
+	ToolCallCount   int              `json:"tool_call_count"`
+	ToolResultCount int              `json:"tool_result_count"`
+	Usage           Usage            `json:"usage"`
+	ModelUsage      map[string]Usage `json:"model_usage,omitempty"`    // Usage broken down by model
+	Subagents       []Subagent       `json:"subagents,omitempty"`      // set by LinkSubagents
+	ActiveLeaf      string           `json:"active_leaf,omitempty"`    // uuid of the latest message; its path is the live conversation
+	Branches        int              `json:"branches"`                 // conversation branches from rewinds and edits
+	ContinuesFrom   string           `json:"continues_from,omitempty"` // ID of the session this one resumed, set by ResolveSummaries and LinkContinuations
+	Warnings        []string         `json:"warnings,omitempty"`       // lines that couldn't be parsed
+	Root            string           `json:"root"`                     // name of the session root it was found in
+	ConfigDir       string           `json:"config_dir,omitempty"`     // CLAUDE_CONFIG_DIR to resume it with; empty for ~/.claude
+
+	// Set only for subagent transcripts, which LinkSubagents folds into
+	// their parent session
+	ParentID string `json:"-"`
+	Prompt   string `json:"-"` // the prompt the subagent was started with
+
+	// Inputs to ResolveSummaries and LinkContinuations
+	LeafUUIDs        []string          `json:"-"` // branch tips, oldest first
+	LeafSummaries    map[string]string `json:"-"` // summary lines in this file, by the leaf they describe
+	SummaryLeaves    []string          `json:"-"` // keys of LeafSummaries in file order
+	PromptSummary    bool              `json:"-"` // Summary is the first prompt; no summary line described this file
+	RootUUID         string            `json:"-"` // first message
+	ExternalParent   string            `json:"-"` // parent of a message that isn't in this file
+	ForeignSessionID string            `json:"-"` // a sessionId other than this file's own
+
+	// Activity by local date (DayLayout), for stats: messages on the active
+	// branch and tokens used
+	Days map[string]DayActivity `json:"-"`
+}
+
+// Subagent is a subagent (sidechain) run started by a session, usually
+// through the Task tool. Its transcript is a separate agent-*.jsonl file.
+type Subagent struct {
+	ID        string    `json:"id"`
+	FilePath  string    `json:"file_path"`
+	Prompt    string    `json:"prompt"`
+	StartedAt time.Time `json:"started_at"`
+	MsgCount  int       `json:"msg_count"`
+}
+
+// Duration is the time between the first and last message
+func (s Session) Duration() time.Duration {
+	return s.LastActivityAt.Sub(s.StartedAt)
+}
+
+// Usage is the token usage reported by the API for assistant messages
+type Usage struct {
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens"`
+}
+
+// DayLayout is the format of the keys of Session.Days
+const DayLayout = "2006-01-02"
+
+// DayActivity is what happened in a session on one day
+type DayActivity struct {
+	Messages int
+	Usage    Usage
+}
+
+// Add accumulates another usage into u
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheReadTokens += o.CacheReadTokens
+	u.CacheCreationTokens += o.CacheCreationTokens
+}
+
+// Total returns the sum of all token counts
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheCreationTokens
 }
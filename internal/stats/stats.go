@@ -0,0 +1,207 @@
+// Package stats aggregates usage analytics across sessions.
+package stats
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// Report summarizes a set of sessions. The ranked tables hold at most the
+// top N rows requested from Compute.
+type Report struct {
+	Sessions int           `json:"sessions"`
+	Projects int           `json:"projects"`
+	Messages int           `json:"messages"`
+	Bytes    int64         `json:"bytes"`
+	Usage    session.Usage `json:"usage"`
+
+	ByProject []Group        `json:"by_project"`
+	ByDay     []Group        `json:"by_day"`
+	ByWeek    []Group        `json:"by_week"`
+	Branches  []Group        `json:"branches"`
+	Longest   []SessionEntry `json:"longest"`
+}
+
+// Group is one row of a breakdown: a project, branch, day or week
+type Group struct {
+	Key      string        `json:"key"`
+	Project  string        `json:"project,omitempty"` // set for branches
+	Sessions int           `json:"sessions"`
+	Messages int           `json:"messages"`
+	Bytes    int64         `json:"bytes"`
+	Usage    session.Usage `json:"usage"`
+}
+
+// SessionEntry identifies a single session in a ranking
+type SessionEntry struct {
+	ID       string `json:"id"`
+	Project  string `json:"project"`
+	Summary  string `json:"summary"`
+	Messages int    `json:"messages"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// Compute aggregates sessions into a report, keeping the top rows of each
+// table. Messages and tokens are attributed to the day and week they were
+// sent, so a session running over several days counts toward each. Days
+// and weeks are most recent first; the other tables are ranked by message
+// count.
+func Compute(sessions []session.Session, top int) Report {
+	var r Report
+	projects := make(map[string]*Group)
+	days := make(map[string]*Group)
+	weeks := make(map[string]*Group)
+	branches := make(map[string]*Group)
+
+	for _, s := range sessions {
+		msgs := messages(s)
+		r.Sessions++
+		r.Messages += msgs
+		r.Bytes += s.Size
+		r.Usage.Add(s.Usage)
+
+		add(projects, s.ProjectPath, s)
+		addActivity(days, weeks, s)
+		if s.GitBranch != "" {
+			// Branch names like main repeat across projects, so key by both
+			add(branches, s.ProjectPath+"\x00"+s.GitBranch, s)
+		}
+	}
+	r.Projects = len(projects)
+
+	r.ByProject = ranked(projects, top)
+	r.ByDay = recent(days, top)
+	r.ByWeek = recent(weeks, top)
+	r.Branches = ranked(branches, top)
+	for i := range r.Branches {
+		b := &r.Branches[i]
+		b.Project, b.Key, _ = strings.Cut(b.Key, "\x00")
+	}
+
+	longest := make([]session.Session, len(sessions))
+	copy(longest, sessions)
+	sort.SliceStable(longest, func(i, j int) bool {
+		return messages(longest[i]) > messages(longest[j])
+	})
+	for _, s := range truncate(longest, top) {
+		r.Longest = append(r.Longest, SessionEntry{
+			ID:       s.ID,
+			Project:  s.ProjectPath,
+			Summary:  s.Summary,
+			Messages: messages(s),
+			Bytes:    s.Size,
+		})
+	}
+
+	return r
+}
+
+func messages(s session.Session) int {
+	return s.UserMsgCount + s.AsstMsgCount
+}
+
+func add(groups map[string]*Group, key string, s session.Session) {
+	g := group(groups, key)
+	g.Sessions++
+	g.Messages += messages(s)
+	g.Bytes += s.Size
+	g.Usage.Add(s.Usage)
+}
+
+// addActivity adds a session to the days and weeks it was active in. Its
+// size on disk goes to the day of its last message, as do any messages
+// without a timestamp.
+func addActivity(days, weeks map[string]*Group, s session.Session) {
+	lastDay := s.LastActivityAt.Format(session.DayLayout)
+	activity := maps.Clone(s.Days)
+	if activity == nil {
+		activity = make(map[string]session.DayActivity)
+	}
+	dated := 0
+	for _, a := range activity {
+		dated += a.Messages
+	}
+	if len(activity) == 0 {
+		activity[lastDay] = session.DayActivity{Messages: messages(s), Usage: s.Usage}
+	} else if rest := messages(s) - dated; rest > 0 {
+		a := activity[lastDay]
+		a.Messages += rest
+		activity[lastDay] = a
+	}
+
+	counted := make(map[string]bool)
+	for day, a := range activity {
+		t, err := time.ParseInLocation(session.DayLayout, day, time.Local)
+		if err != nil {
+			continue
+		}
+		for _, g := range []*Group{group(days, day), group(weeks, weekKey(t))} {
+			if !counted[g.Key] {
+				counted[g.Key] = true
+				g.Sessions++
+			}
+			g.Messages += a.Messages
+			g.Usage.Add(a.Usage)
+		}
+	}
+
+	group(days, lastDay).Bytes += s.Size
+	group(weeks, weekKey(s.LastActivityAt)).Bytes += s.Size
+}
+
+// weekKey names the ISO week of t, e.g. 2026-W03
+func weekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// group returns the group for key, adding it if it is new
+func group(groups map[string]*Group, key string) *Group {
+	g, ok := groups[key]
+	if !ok {
+		g = &Group{Key: key}
+		groups[key] = g
+	}
+	return g
+}
+
+// ranked returns the groups with the most messages first
+func ranked(groups map[string]*Group, top int) []Group {
+	out := flatten(groups)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Messages != out[j].Messages {
+			return out[i].Messages > out[j].Messages
+		}
+		return out[i].Key < out[j].Key
+	})
+	return truncate(out, top)
+}
+
+// recent returns date-keyed groups newest first
+func recent(groups map[string]*Group, top int) []Group {
+	out := flatten(groups)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key > out[j].Key
+	})
+	return truncate(out, top)
+}
+
+func flatten(groups map[string]*Group) []Group {
+	out := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	return out
+}
+
+func truncate[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,263 @@
+// Package trash moves deleted session files aside so they can be restored.
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+const metaSuffix = ".meta.json"
+
+// Item is a session file in the trash, with enough metadata to restore it
+type Item struct {
+	Name         string    `json:"-"` // trash entry name, unique per deletion
+	SessionID    string    `json:"session_id"`
+	OriginalPath string    `json:"original_path"`
+	Subagents    []string  `json:"subagents,omitempty"` // original paths of its subagent transcripts, trashed with it
+	ProjectPath  string    `json:"project_path"`
+	Summary      string    `json:"summary"`
+	Size         int64     `json:"size"`
+	DeletedAt    time.Time `json:"deleted_at"`
+}
+
+// Trash is a directory of deleted session files and their metadata
+type Trash struct {
+	dir string
+}
+
+// New returns the trash in the claude-fzf data directory
+func New() *Trash {
+	return &Trash{dir: filepath.Join(dataDir(), "trash")}
+}
+
+// dataDir returns the claude-fzf data directory, honoring XDG_DATA_HOME
+func dataDir() string {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "claude-fzf")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "claude-fzf")
+}
+
+// Dir returns the trash directory (for help text)
+func (t *Trash) Dir() string {
+	return t.dir
+}
+
+// Move moves a session file and its subagent transcripts into the trash
+// and records where they came from
+func (t *Trash) Move(s session.Session) (Item, error) {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return Item{}, err
+	}
+
+	now := time.Now()
+	item := Item{
+		Name:         fmt.Sprintf("%s.%d", s.ID, now.UnixNano()),
+		SessionID:    s.ID,
+		OriginalPath: s.FilePath,
+		ProjectPath:  s.ProjectPath,
+		Summary:      s.Summary,
+		Size:         s.Size,
+		DeletedAt:    now,
+	}
+	for _, sub := range s.Subagents {
+		if _, err := os.Stat(sub.FilePath); err == nil {
+			item.Subagents = append(item.Subagents, sub.FilePath)
+		}
+	}
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return Item{}, err
+	}
+	if err := os.WriteFile(t.metaPath(item), data, 0644); err != nil {
+		return Item{}, err
+	}
+	if err := t.moveFiles(item, true); err != nil {
+		os.Remove(t.metaPath(item))
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// List returns the items in the trash, most recently deleted first
+func (t *Trash) List() ([]Item, error) {
+	entries, err := os.ReadDir(t.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), metaSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(t.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var item Item
+		if json.Unmarshal(data, &item) != nil {
+			continue
+		}
+		item.Name = strings.TrimSuffix(e.Name(), metaSuffix)
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Find returns the most recently deleted item whose session ID is id or
+// uniquely starts with it
+func (t *Trash) Find(id string) (Item, error) {
+	items, err := t.List()
+	if err != nil {
+		return Item{}, err
+	}
+
+	for _, item := range items {
+		if item.SessionID == id {
+			return item, nil
+		}
+	}
+
+	var match *Item
+	for i := range items {
+		if strings.HasPrefix(items[i].SessionID, id) {
+			if match != nil && match.SessionID != items[i].SessionID {
+				return Item{}, fmt.Errorf("prefix %q is ambiguous", id)
+			}
+			if match == nil {
+				match = &items[i]
+			}
+		}
+	}
+	if match == nil {
+		return Item{}, fmt.Errorf("no trashed session matches %q", id)
+	}
+	return *match, nil
+}
+
+// Restore moves an item and its subagent transcripts back to their
+// original paths. It refuses to overwrite a file that has since been
+// created at any of them.
+func (t *Trash) Restore(item Item) error {
+	for _, path := range append([]string{item.OriginalPath}, item.Subagents...) {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := t.moveFiles(item, false); err != nil {
+		return err
+	}
+	return os.Remove(t.metaPath(item))
+}
+
+// Remove permanently deletes an item
+func (t *Trash) Remove(item Item) error {
+	for _, f := range t.files(item) {
+		if err := os.Remove(f.trashed); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Remove(t.metaPath(item))
+}
+
+// trashedFile is one of an item's files, at its original path and in the
+// trash
+type trashedFile struct {
+	original, trashed string
+}
+
+// files returns the session file of an item, then its subagent transcripts
+func (t *Trash) files(item Item) []trashedFile {
+	files := []trashedFile{{item.OriginalPath, t.dataPath(item)}}
+	for i, path := range item.Subagents {
+		files = append(files, trashedFile{path, filepath.Join(t.dir, fmt.Sprintf("%s.agent-%d.jsonl", item.Name, i))})
+	}
+	return files
+}
+
+// moveFiles moves an item's files into the trash, or back out of it. If
+// one can't be moved, those already moved are put back.
+func (t *Trash) moveFiles(item Item, toTrash bool) error {
+	files := t.files(item)
+	for i, f := range files {
+		src, dst := f.original, f.trashed
+		if !toTrash {
+			src, dst = dst, src
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+		}
+		if err := moveFile(src, dst); err != nil {
+			for _, done := range files[:i] {
+				if toTrash {
+					moveFile(done.trashed, done.original)
+				} else {
+					moveFile(done.original, done.trashed)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Trash) dataPath(item Item) string {
+	return filepath.Join(t.dir, item.Name+".jsonl")
+}
+
+func (t *Trash) metaPath(item Item) string {
+	return filepath.Join(t.dir, item.Name+metaSuffix)
+}
+
+// moveFile renames src to dst, falling back to copy and remove when they
+// are on different filesystems. The modification time is preserved so the
+// session cache stays valid.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// collapseChains folds continuation chains (sessions resumed into new
+// files) into their newest link. sessions must be sorted newest first; the
+// result keeps that order, with each chain's older links, newest first,
+// keyed by the newest link's file path.
+func collapseChains(sessions []session.Session) ([]session.Session, map[string][]session.Session) {
+	byID := make(map[string]int, len(sessions))
+	for i, s := range sessions {
+		byID[s.ID] = i
+	}
+
+	var heads []session.Session
+	history := make(map[string][]session.Session)
+	claimed := make(map[string]bool)
+	for _, s := range sessions {
+		if claimed[s.FilePath] {
+			continue
+		}
+		claimed[s.FilePath] = true
+		heads = append(heads, s)
+
+		for id := s.ContinuesFrom; id != ""; {
+			i, ok := byID[id]
+			if !ok || claimed[sessions[i].FilePath] {
+				break
+			}
+			claimed[sessions[i].FilePath] = true
+			history[s.FilePath] = append(history[s.FilePath], sessions[i])
+			id = sessions[i].ContinuesFrom
+		}
+	}
+	return heads, history
+}
+
+// toggleChain shows or hides the older links of the highlighted session's
+// continuation chain. On an older link it collapses the chain and moves the
+// cursor back to the newest link.
+func (m *pickerModel) toggleChain() {
+	if m.mode != "sessions" || m.sessionCursor >= len(m.filteredSessions) || m.selectedProject == nil {
+		return
+	}
+	path := m.filteredSessions[m.sessionCursor].FilePath
+	if head, ok := m.chainHead[path]; ok {
+		path = head
+	}
+	if len(m.selectedProject.History[path]) == 0 {
+		m.status = "No earlier sessions in this conversation"
+		return
+	}
+
+	m.expandedChains[path] = !m.expandedChains[path]
+	m.applySessionFilter()
+	for i, s := range m.filteredSessions {
+		if s.FilePath == path {
+			m.sessionCursor = i
+			break
+		}
+	}
+}
+
+// chainColumn renders the column before a session line: an arrow on
+// sessions with hidden or shown history, and a hook on older links
+func (m *pickerModel) chainColumn(s session.Session) string {
+	if _, ok := m.chainHead[s.FilePath]; ok {
+		return dimStyle.Render("↳ ")
+	}
+	if m.selectedProject == nil || len(m.selectedProject.History[s.FilePath]) == 0 {
+		return "  "
+	}
+	if m.expandedChains[s.FilePath] {
+		return countStyle.Render("▾ ")
+	}
+	return countStyle.Render("▸ ")
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jh3/claude-fzf/internal/export"
+	"github.com/jh3/claude-fzf/internal/session"
+	"github.com/jh3/claude-fzf/internal/stats"
+	"github.com/jh3/claude-fzf/internal/trash"
+)
+
+var markStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("208"))
+
+// deletedSession pairs a trashed session with its trash entry, for undo
+type deletedSession struct {
+	session session.Session
+	item    trash.Item
+}
+
+// toggleMark flips the mark on the highlighted session and moves the cursor
+// by step, like fzf's tab/shift-tab
+func (m *pickerModel) toggleMark(step int) {
+	if m.mode != "sessions" || len(m.filteredSessions) == 0 {
+		return
+	}
+	path := m.filteredSessions[m.sessionCursor].FilePath
+	if m.marked[path] {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = true
+	}
+	m.sessionCursor = min(max(0, m.sessionCursor+step), len(m.filteredSessions)-1)
+}
+
+// markAllVisible marks every session in the filtered list, or clears them
+// all if they are already marked
+func (m *pickerModel) markAllVisible() {
+	if m.mode != "sessions" {
+		return
+	}
+	allMarked := true
+	for _, s := range m.filteredSessions {
+		if !m.marked[s.FilePath] {
+			allMarked = false
+			break
+		}
+	}
+	for _, s := range m.filteredSessions {
+		if allMarked {
+			delete(m.marked, s.FilePath)
+		} else {
+			m.marked[s.FilePath] = true
+		}
+	}
+}
+
+// actionTargets returns the sessions a bulk action applies to: the marked
+// sessions if any, otherwise the highlighted one
+func (m *pickerModel) actionTargets() []session.Session {
+	if len(m.marked) > 0 {
+		var targets []session.Session
+		for _, s := range m.allSessions {
+			if m.marked[s.FilePath] {
+				targets = append(targets, s)
+			}
+		}
+		return targets
+	}
+	if m.mode == "sessions" && m.sessionCursor < len(m.filteredSessions) {
+		return []session.Session{m.filteredSessions[m.sessionCursor]}
+	}
+	return nil
+}
+
+// confirmPrompt describes the pending action. Deletes show the count and
+// size on disk.
+func (m *pickerModel) confirmPrompt() string {
+	targets := m.actionTargets()
+	if m.confirm == "export" {
+		if len(targets) == 1 {
+			return fmt.Sprintf("Export this session to %s? (y/n)", exportName(targets[0]))
+		}
+		return fmt.Sprintf("Export %d sessions as Markdown to the current directory? (y/n)", len(targets))
+	}
+
+	var size int64
+	for _, s := range targets {
+		size += s.Size
+	}
+	if len(targets) == 1 {
+		return fmt.Sprintf("Move this session to trash (%s)? (y/n)", stats.FormatBytes(size))
+	}
+	return fmt.Sprintf("Move %d sessions to trash (%s)? (y/n)", len(targets), stats.FormatBytes(size))
+}
+
+// deleteTargets moves every action target to the trash and drops them
+// from the picker, reporting the outcome in the status line
+func (m *pickerModel) deleteTargets() {
+	targets := m.actionTargets()
+	removed := make(map[string]bool, len(targets))
+	var deleted []deletedSession
+	var firstErr error
+
+	for _, s := range targets {
+		item, err := m.trash.Move(s)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		removed[s.FilePath] = true
+		deleted = append(deleted, deletedSession{session: s, item: item})
+	}
+
+	m.removeSessions(removed)
+	m.marked = make(map[string]bool)
+	if len(deleted) > 0 {
+		m.lastDeleted = deleted
+	}
+
+	switch {
+	case firstErr != nil:
+		m.status = fmt.Sprintf("Moved %d of %d to trash: %v", len(removed), len(targets), firstErr)
+	case len(removed) == 1:
+		m.status = "Moved 1 session to trash • ctrl-z: undo"
+	default:
+		m.status = fmt.Sprintf("Moved %d sessions to trash • ctrl-z: undo", len(removed))
+	}
+}
+
+// exportDoneMsg reports the outcome of an export started by exportTargets
+type exportDoneMsg struct {
+	written []string // files written
+	total   int
+	err     error // the first failure, if any
+}
+
+// exportTargets writes every action target as Markdown to <id>.md in the
+// current directory, in the background
+func (m *pickerModel) exportTargets() tea.Cmd {
+	targets := m.actionTargets()
+	m.marked = make(map[string]bool)
+	m.status = "Exporting…"
+
+	return func() tea.Msg {
+		msg := exportDoneMsg{total: len(targets)}
+		for _, s := range targets {
+			name, err := exportFile(s)
+			if err != nil {
+				if msg.err == nil {
+					msg.err = err
+				}
+				continue
+			}
+			msg.written = append(msg.written, name)
+		}
+		return msg
+	}
+}
+
+// exportDone reports a finished export in the status line
+func (m *pickerModel) exportDone(msg exportDoneMsg) {
+	switch {
+	case msg.err != nil:
+		m.status = fmt.Sprintf("Exported %d of %d: %v", len(msg.written), msg.total, msg.err)
+	case len(msg.written) == 1:
+		m.status = "Exported to " + msg.written[0]
+	default:
+		m.status = fmt.Sprintf("Exported %d sessions", len(msg.written))
+	}
+}
+
+func exportName(s session.Session) string {
+	return s.ID + ".md"
+}
+
+// exportFile writes s to exportName(s), or to <id>-2.md and so on if that
+// file exists, and returns the name written
+func exportFile(s session.Session) (string, error) {
+	f, err := createUnique(s.ID, ".md")
+	if err != nil {
+		return "", err
+	}
+	if err := export.Session(f, s, export.Options{Format: "md"}); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// createUnique creates base+ext, numbering the name if it is taken rather
+// than overwriting an existing file
+func createUnique(base, ext string) (*os.File, error) {
+	name := base + ext
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) || n > 100 {
+			return f, err
+		}
+		name = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+// undoDelete restores the sessions of the most recent delete
+func (m *pickerModel) undoDelete() {
+	if len(m.lastDeleted) == 0 {
+		m.status = "Nothing to undo"
+		return
+	}
+
+	restored := 0
+	var firstErr error
+	for _, d := range m.lastDeleted {
+		if err := m.trash.Restore(d.item); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		m.allSessions = append(m.allSessions, d.session)
+		restored++
+	}
+	m.lastDeleted = nil
+
+	m.rebuildProjects()
+	if m.mode == "sessions" {
+		m.refreshSelectedProject()
+		if m.selectedProject != nil {
+			m.applySessionFilter()
+		}
+	}
+	if m.mode != "sessions" || m.selectedProject == nil {
+		m.mode = "projects"
+		m.applyProjectFilter()
+	}
+
+	if firstErr != nil {
+		m.status = fmt.Sprintf("Restored %d: %v", restored, firstErr)
+	} else {
+		m.status = fmt.Sprintf("Restored %d from trash", restored)
+	}
+}
+
+// removeSessions drops sessions from the picker and refreshes the views,
+// returning to the project list if the selected project became empty
+func (m *pickerModel) removeSessions(paths map[string]bool) {
+	var kept []session.Session
+	for _, s := range m.allSessions {
+		if !paths[s.FilePath] {
+			kept = append(kept, s)
+		}
+	}
+	m.allSessions = kept
+
+	m.rebuildProjects()
+	if m.mode != "sessions" {
+		m.applyProjectFilter()
+		return
+	}
+
+	m.refreshSelectedProject()
+	if m.selectedProject == nil || len(m.selectedProject.Sessions) == 0 {
+		m.mode = "projects"
+		m.selectedProject = nil
+		m.applyProjectFilter()
+	} else {
+		m.applySessionFilter()
+	}
+}
+
+// refreshSelectedProject points selectedProject at the rebuilt group for
+// the same project, or nil if it no longer exists
+func (m *pickerModel) refreshSelectedProject() {
+	if m.selectedProject == nil {
+		return
+	}
+	path := m.selectedProject.ProjectPath
+	m.selectedProject = nil
+	for i := range m.projects {
+		if m.projects[i].ProjectPath == path {
+			m.selectedProject = &m.projects[i]
+			return
+		}
+	}
+}
+
+// gutter renders the two-column list prefix: cursor and mark
+func gutter(selected, marked bool) string {
+	cursor := " "
+	if selected {
+		cursor = cursorStyle.Render(">")
+	}
+	mark := " "
+	if marked {
+		mark = markStyle.Render("•")
+	}
+	return cursor + mark
+}
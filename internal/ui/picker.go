@@ -4,14 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/jh3/claude-fzf/internal/config"
+	"github.com/jh3/claude-fzf/internal/fuzzy"
+	"github.com/jh3/claude-fzf/internal/query"
 	"github.com/jh3/claude-fzf/internal/session"
+	"github.com/jh3/claude-fzf/internal/trash"
 )
 
 // Action represents what the user wants to do with the selected session
@@ -31,12 +37,32 @@ type Result struct {
 	ProjectPath string // for ActionNewProject
 }
 
+// Options configures the picker
+type Options struct {
+	ShowEmpty   bool         // start with empty sessions visible
+	ProjectsDir string       // base directory for new projects
+	Index       ContentIndex // full-text index for content search
+	Trash       *trash.Trash // where deleted sessions go
+	Prices      config.Prices
+	Updates     <-chan session.Progress // sessions parsed, or rescanned, after the picker opens
+}
+
+// ContentIndex answers full-text queries against session conversations
+type ContentIndex interface {
+	Update(sessions []session.Session, complete bool) error
+	Contains(path, query string) bool
+}
+
+// indexReadyMsg is sent once the content index has been brought up to date
+type indexReadyMsg struct{}
+
 // ProjectGroup holds sessions grouped by project path
 type ProjectGroup struct {
 	ProjectPath string
 	ProjectName string
-	Sessions    []session.Session
-	LatestMod   string // formatted date of most recent session
+	Sessions    []session.Session            // newest link of each conversation
+	History     map[string][]session.Session // older links of resumed conversations, by newest link's file path
+	LatestMod   string                       // formatted date of most recent session
 }
 
 // pickerModel is the bubbletea model for the session picker
@@ -44,14 +70,29 @@ type pickerModel struct {
 	// Data
 	allSessions []session.Session
 	projects    []ProjectGroup
+	rootWidth   int // width of the root column; 0 when every session is from one root
 
 	// View state
-	mode             string // "projects", "sessions", "newproject"
-	projectCursor    int
-	sessionCursor    int
-	selectedProject  *ProjectGroup
-	filter           textinput.Model
-	showEmpty        bool
+	mode            string // "projects", "sessions", "newproject"
+	projectCursor   int
+	sessionCursor   int
+	selectedProject *ProjectGroup
+	filter          textinput.Model
+	query           fuzzy.Query // free-text part of the filter, kept for highlighting
+	queryErr        error       // invalid qualifier in the filter, if any
+	showEmpty       bool
+
+	// Content search
+	index         ContentIndex
+	contentSearch bool // also match conversation text
+	indexing      bool
+	indexReady    bool
+	reindex       bool // a complete scan arrived while indexing
+
+	// Transcript preview
+	showTranscript  bool
+	expandSubagents bool // show subagent runs inline in the transcript
+	transcript      transcriptState
 
 	// Filtered views
 	filteredProjects []ProjectGroup
@@ -62,9 +103,21 @@ type pickerModel struct {
 	height int
 
 	// Actions
-	result        Result
-	confirmDelete bool
-	quitting      bool
+	result      Result
+	marked      map[string]bool // multi-selected sessions, by file path
+	confirm     string          // action awaiting y/n: "delete" or "export"
+	trash       *trash.Trash
+	lastDeleted []deletedSession // most recent delete, for undo
+
+	expandedChains map[string]bool   // conversations showing their older links, by newest link's path
+	chainHead      map[string]string // older links in filteredSessions -> newest link's path
+	status         string            // outcome of the last action, shown in the footer
+	quitting       bool
+
+	prices  config.Prices           // for cost estimates in previews
+	updates <-chan session.Progress // scans to show as they arrive
+	parsed  int                     // files parsed so far by a scan still in progress
+	toParse int
 
 	// New project mode
 	projectsDir  string
@@ -80,8 +133,15 @@ var (
 	previewHeader = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
 	confirmStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 	countStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	warnStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	matchColor    = lipgloss.Color("208")
+	plainStyle    = lipgloss.NewStyle()
 )
 
+// contentOnlyScore ranks sessions matched only through content search below
+// every fuzzy match
+const contentOnlyScore = -1 << 30
+
 func groupSessionsByProject(sessions []session.Session) []ProjectGroup {
 	groups := make(map[string]*ProjectGroup)
 
@@ -106,25 +166,26 @@ func groupSessionsByProject(sessions []session.Session) []ProjectGroup {
 		}
 	}
 
-	// Convert to slice and sort each group's sessions by ModTime
+	// Convert to slice and sort each group's sessions by last activity
 	var result []ProjectGroup
 	for _, g := range groups {
 		sort.Slice(g.Sessions, func(i, j int) bool {
-			return g.Sessions[i].ModTime.After(g.Sessions[j].ModTime)
+			return g.Sessions[i].LastActivityAt.After(g.Sessions[j].LastActivityAt)
 		})
-		g.LatestMod = g.Sessions[0].ModTime.Format("01/02 15:04")
+		g.Sessions, g.History = collapseChains(g.Sessions)
+		g.LatestMod = g.Sessions[0].LastActivityAt.Format("01/02 15:04")
 		result = append(result, *g)
 	}
 
 	// Sort groups by most recent session
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Sessions[0].ModTime.After(result[j].Sessions[0].ModTime)
+		return result[i].Sessions[0].LastActivityAt.After(result[j].Sessions[0].LastActivityAt)
 	})
 
 	return result
 }
 
-func newPickerModel(sessions []session.Session, showEmpty bool, projectsDir string) pickerModel {
+func newPickerModel(sessions []session.Session, opts Options) pickerModel {
 	ti := textinput.New()
 	ti.Placeholder = "Filter..."
 	ti.Focus()
@@ -132,13 +193,23 @@ func newPickerModel(sessions []session.Session, showEmpty bool, projectsDir stri
 	ti.Width = 40
 
 	m := pickerModel{
-		allSessions: sessions,
-		filter:      ti,
-		showEmpty:   showEmpty,
-		mode:        "projects",
-		width:       80,
-		height:      24,
-		projectsDir: projectsDir,
+		allSessions:    sessions,
+		filter:         ti,
+		showEmpty:      opts.ShowEmpty,
+		mode:           "projects",
+		width:          80,
+		height:         24,
+		projectsDir:    opts.ProjectsDir,
+		index:          opts.Index,
+		marked:         make(map[string]bool),
+		expandedChains: make(map[string]bool),
+		chainHead:      make(map[string]string),
+		trash:          opts.Trash,
+		prices:         opts.Prices,
+		updates:        opts.Updates,
+	}
+	if m.trash == nil {
+		m.trash = trash.New()
 	}
 	m.rebuildProjects()
 	m.applyProjectFilter()
@@ -155,24 +226,51 @@ func (m *pickerModel) rebuildProjects() {
 		filtered = append(filtered, s)
 	}
 	m.projects = groupSessionsByProject(filtered)
+
+	// Only label sessions by root when there is more than one to tell apart
+	roots := make(map[string]bool)
+	m.rootWidth = 0
+	for _, s := range m.allSessions {
+		roots[s.Root] = true
+		m.rootWidth = max(m.rootWidth, min(len([]rune(s.Root)), maxRootWidth))
+	}
+	if len(roots) < 2 {
+		m.rootWidth = 0
+	}
+}
+
+// maxRootWidth is the widest the root column gets
+const maxRootWidth = 10
+
+// rootColumn renders the session's root name, or nothing when there is only
+// one root
+func (m *pickerModel) rootColumn(s session.Session) string {
+	if m.rootWidth == 0 {
+		return ""
+	}
+	return dimStyle.Render(fixedWidth(s.Root, m.rootWidth)) + "  "
 }
 
 func (m *pickerModel) applyProjectFilter() {
-	query := strings.ToLower(m.filter.Value())
+	q := m.parseFilter()
 	m.filteredProjects = nil
+	var scores []int
 
 	for _, p := range m.projects {
-		if query != "" {
-			searchText := strings.ToLower(p.ProjectPath + " " + p.ProjectName)
-			// Also search session summaries and branches
-			for _, s := range p.Sessions {
-				searchText += " " + strings.ToLower(s.Summary+" "+s.GitBranch)
-			}
-			if !strings.Contains(searchText, query) {
+		score, ok := scoreProject(p, q)
+		if !ok {
+			if !m.projectContentMatch(p, q) {
 				continue
 			}
+			score = contentOnlyScore
 		}
 		m.filteredProjects = append(m.filteredProjects, p)
+		scores = append(scores, score)
+	}
+
+	// Rank by score; ties keep the most-recent-first order
+	if !q.Text.Empty() {
+		sort.Stable(byScore[ProjectGroup]{items: m.filteredProjects, scores: scores})
 	}
 
 	if m.projectCursor >= len(m.filteredProjects) {
@@ -185,17 +283,47 @@ func (m *pickerModel) applySessionFilter() {
 		return
 	}
 
-	query := strings.ToLower(m.filter.Value())
-	m.filteredSessions = nil
-
-	for _, s := range m.selectedProject.Sessions {
-		if query != "" {
-			searchText := strings.ToLower(s.Summary + " " + s.GitBranch)
-			if !strings.Contains(searchText, query) {
-				continue
+	q := m.parseFilter()
+	var heads []session.Session
+	var scores []int
+
+	// A conversation matches if any of its links does
+	for _, head := range m.selectedProject.Sessions {
+		best, matched := 0, false
+		for _, s := range append([]session.Session{head}, m.selectedProject.History[head.FilePath]...) {
+			score, ok := q.Match(s)
+			if !ok {
+				if q.Raw == "" || !q.Filter.Match(s) || !m.contentMatch(s, q.Raw) {
+					continue
+				}
+				score = contentOnlyScore
 			}
+			if !matched || score > best {
+				best, matched = score, true
+			}
+		}
+		if matched {
+			heads = append(heads, head)
+			scores = append(scores, best)
+		}
+	}
+
+	if !q.Text.Empty() {
+		sort.Stable(byScore[session.Session]{items: heads, scores: scores})
+	}
+
+	// Expanded chains list their older links below the newest
+	m.filteredSessions = nil
+	m.chainHead = make(map[string]string)
+	for _, head := range heads {
+		m.filteredSessions = append(m.filteredSessions, head)
+		if !m.expandedChains[head.FilePath] {
+			continue
+		}
+		for _, s := range m.selectedProject.History[head.FilePath] {
+			m.filteredSessions = append(m.filteredSessions, s)
+			m.chainHead[s.FilePath] = head.FilePath
 		}
-		m.filteredSessions = append(m.filteredSessions, s)
 	}
 
 	if m.sessionCursor >= len(m.filteredSessions) {
@@ -203,53 +331,128 @@ func (m *pickerModel) applySessionFilter() {
 	}
 }
 
+// parseFilter parses the filter box into qualifiers and free text,
+// remembering the text for highlighting and any qualifier error for the header
+func (m *pickerModel) parseFilter() query.Query {
+	q, err := query.Parse(m.filter.Value(), time.Now())
+	m.query = q.Text
+	m.queryErr = err
+	return q
+}
+
+// scoreProject returns the best match score of a project: either its own
+// name and path, or one of its sessions, matched as list matches them.
+// Only sessions passing the query's qualifiers count.
+func scoreProject(p ProjectGroup, q query.Query) (int, bool) {
+	var candidates []string
+	add := func(s session.Session) {
+		if q.Filter.Match(s) {
+			candidates = append(candidates, query.SearchText(s))
+		}
+	}
+	for _, s := range p.Sessions {
+		add(s)
+		for _, older := range p.History[s.FilePath] {
+			add(older)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	candidates = append(candidates, p.ProjectName+" "+p.ProjectPath)
+
+	best, matched := 0, false
+	for _, text := range candidates {
+		if score, _, ok := q.Text.Match(text); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+// byScore sorts items by descending score, keeping scores in step
+type byScore[T any] struct {
+	items  []T
+	scores []int
+}
+
+func (b byScore[T]) Len() int           { return len(b.items) }
+func (b byScore[T]) Less(i, j int) bool { return b.scores[i] > b.scores[j] }
+func (b byScore[T]) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
+
+// contentMatch reports whether a session's conversation contains text.
+// It only matches in content search mode, once the index is built.
+func (m *pickerModel) contentMatch(s session.Session, text string) bool {
+	return m.contentSearch && m.indexReady && m.index.Contains(s.FilePath, text)
+}
+
+func (m *pickerModel) projectContentMatch(p ProjectGroup, q query.Query) bool {
+	if q.Raw == "" {
+		return false
+	}
+	for _, s := range p.Sessions {
+		for _, link := range append([]session.Session{s}, p.History[s.FilePath]...) {
+			if q.Filter.Match(link) && m.contentMatch(link, q.Raw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildIndex brings the content index up to date in the background. While
+// the startup scan is still streaming in, only the sessions parsed so far
+// are indexed and nothing is dropped from the index.
+func (m *pickerModel) buildIndex() tea.Cmd {
+	index := m.index
+	sessions := m.allSessions
+	complete := m.parsed >= m.toParse
+	return func() tea.Msg {
+		index.Update(sessions, complete)
+		return indexReadyMsg{}
+	}
+}
+
 func (m pickerModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.waitForSessions())
 }
 
 func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// Keep the transcript pane in step with the highlighted session
+	pm := next.(pickerModel)
+	if load := pm.syncTranscript(); load != nil {
+		return pm, tea.Batch(cmd, load)
+	}
+	return pm, cmd
+}
+
+func (m pickerModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle delete confirmation mode
-		if m.confirmDelete {
+		m.status = ""
+
+		// Handle confirmation mode
+		if m.confirm != "" {
 			switch msg.String() {
 			case "y", "Y":
-				if m.mode == "sessions" && len(m.filteredSessions) > 0 {
-					sess := m.filteredSessions[m.sessionCursor]
-					os.Remove(sess.FilePath)
-
-					// Remove from allSessions
-					for i, s := range m.allSessions {
-						if s.FilePath == sess.FilePath {
-							m.allSessions = append(m.allSessions[:i], m.allSessions[i+1:]...)
-							break
-						}
-					}
-
-					// Rebuild and refilter
-					m.rebuildProjects()
-
-					// Update selected project reference
-					for i := range m.filteredProjects {
-						if m.filteredProjects[i].ProjectPath == m.selectedProject.ProjectPath {
-							m.selectedProject = &m.filteredProjects[i]
-							break
-						}
-					}
-
-					// If project has no more sessions, go back to project view
-					if m.selectedProject == nil || len(m.selectedProject.Sessions) == 0 {
-						m.mode = "projects"
-						m.selectedProject = nil
-						m.applyProjectFilter()
-					} else {
-						m.applySessionFilter()
+				var cmd tea.Cmd
+				if m.mode == "sessions" {
+					switch m.confirm {
+					case "delete":
+						m.deleteTargets()
+					case "export":
+						cmd = m.exportTargets()
 					}
 				}
-				m.confirmDelete = false
-				return m, nil
+				m.confirm = ""
+				return m, cmd
 			case "n", "N", "esc":
-				m.confirmDelete = false
+				m.confirm = ""
 				return m, nil
 			}
 			return m, nil
@@ -270,6 +473,7 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.mode == "sessions" {
 				m.mode = "projects"
 				m.selectedProject = nil
+				m.marked = make(map[string]bool)
 				m.filter.SetValue("")
 				m.applyProjectFilter()
 				return m, nil
@@ -314,12 +518,21 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.mode == "projects" && len(m.filteredProjects) > 0 {
 				// Expand into project's sessions
 				m.selectedProject = &m.filteredProjects[m.projectCursor]
-				m.filteredSessions = m.selectedProject.Sessions
 				m.sessionCursor = 0
 				m.mode = "sessions"
 				m.filter.SetValue("")
+				m.applySessionFilter()
 				return m, nil
 			}
+			m.toggleMark(1)
+			return m, nil
+
+		case "shift+tab":
+			m.toggleMark(-1)
+			return m, nil
+
+		case "alt+a":
+			m.markAllVisible()
 			return m, nil
 
 		case "ctrl+n":
@@ -336,8 +549,75 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "ctrl+d":
 			if m.mode == "sessions" && len(m.filteredSessions) > 0 {
-				m.confirmDelete = true
+				m.confirm = "delete"
+			}
+			return m, nil
+
+		case "ctrl+e":
+			if m.mode == "sessions" && len(m.filteredSessions) > 0 {
+				m.confirm = "export"
+			}
+			return m, nil
+
+		case "ctrl+z":
+			if m.mode != "newproject" {
+				m.undoDelete()
+			}
+			return m, nil
+
+		case "ctrl+f":
+			if m.mode == "newproject" || m.index == nil {
+				return m, nil
+			}
+			m.contentSearch = !m.contentSearch
+			if m.contentSearch && !m.indexReady && !m.indexing {
+				m.indexing = true
+				return m, m.buildIndex()
+			}
+			if m.mode == "projects" {
+				m.applyProjectFilter()
+			} else if m.mode == "sessions" {
+				m.applySessionFilter()
+			}
+			return m, nil
+
+		case "ctrl+t":
+			if m.mode == "newproject" {
+				return m, nil
 			}
+			m.showTranscript = !m.showTranscript
+			m.transcript = transcriptState{}
+			return m, nil
+
+		case "ctrl+o":
+			if m.mode == "newproject" {
+				return m, nil
+			}
+			m.expandSubagents = !m.expandSubagents
+			if m.expandSubagents {
+				m.status = "Subagent runs expanded in transcript"
+			} else {
+				m.status = "Subagent runs collapsed"
+			}
+			return m, nil
+
+		case "ctrl+r":
+			m.toggleChain()
+			return m, nil
+
+		case "ctrl+b":
+			// Otherwise ctrl+b moves the filter's cursor
+			if m.showTranscript {
+				m.cycleBranch()
+				return m, nil
+			}
+
+		case "shift+up":
+			m.scrollTranscript(-1)
+			return m, nil
+
+		case "shift+down":
+			m.scrollTranscript(1)
 			return m, nil
 
 		case "ctrl+a":
@@ -346,14 +626,13 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.mode == "projects" {
 				m.applyProjectFilter()
 			} else if m.mode == "sessions" {
-				// Update selected project reference
-				for i := range m.projects {
-					if m.projects[i].ProjectPath == m.selectedProject.ProjectPath {
-						m.selectedProject = &m.projects[i]
-						break
-					}
+				m.refreshSelectedProject()
+				if m.selectedProject == nil {
+					m.mode = "projects"
+					m.applyProjectFilter()
+				} else {
+					m.applySessionFilter()
 				}
-				m.applySessionFilter()
 			}
 			return m, nil
 
@@ -406,6 +685,47 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case exportDoneMsg:
+		m.exportDone(msg)
+		return m, nil
+
+	case transcriptLoadedMsg:
+		if msg.path == m.transcript.path && msg.expanded == m.transcript.expanded {
+			m.setTranscript(msg)
+		}
+		return m, nil
+
+	case sessionsMsg:
+		m.parsed, m.toParse = msg.progress.Parsed, msg.progress.Total
+		m.setSessions(msg.progress.Sessions)
+		cmds := []tea.Cmd{m.waitForSessions()}
+		// Bring changed conversations into content search, once the scan
+		// is complete rather than on every partial snapshot
+		if (m.indexReady || m.indexing) && m.parsed >= m.toParse {
+			if m.indexing {
+				m.reindex = true
+			} else {
+				m.indexing = true
+				cmds = append(cmds, m.buildIndex())
+			}
+		}
+		return m, tea.Batch(cmds...)
+
+	case indexReadyMsg:
+		m.indexing = false
+		m.indexReady = true
+		if m.mode == "projects" {
+			m.applyProjectFilter()
+		} else if m.mode == "sessions" {
+			m.applySessionFilter()
+		}
+		if m.reindex {
+			m.reindex = false
+			m.indexing = true
+			return m, m.buildIndex()
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -440,28 +760,22 @@ func (m pickerModel) View() string {
 		}
 		b.WriteString(fmt.Sprintf("%s %s\n\n", header, m.filter.View()))
 	case "sessions":
-		emptyIndicator := ""
-		if m.showEmpty {
-			emptyIndicator = " [+empty]"
+		marked := ""
+		if len(m.marked) > 0 {
+			marked = fmt.Sprintf(", %d marked", len(m.marked))
 		}
-		b.WriteString(fmt.Sprintf("← %s (%d sessions)%s %s\n\n",
+		b.WriteString(fmt.Sprintf("← %s (%d sessions%s)%s %s\n\n",
 			m.selectedProject.ProjectName,
 			len(m.filteredSessions),
-			emptyIndicator,
+			marked,
+			m.indicators(),
 			m.filter.View()))
 	default: // projects
-		emptyIndicator := ""
-		if m.showEmpty {
-			emptyIndicator = " [+empty]"
-		}
 		b.WriteString(fmt.Sprintf("Projects %d%s %s\n\n",
-			len(m.filteredProjects), emptyIndicator, m.filter.View()))
+			len(m.filteredProjects), m.indicators(), m.filter.View()))
 	}
 
-	// Calculate layout
-	listWidth := m.width / 2
-	previewWidth := m.width - listWidth - 3
-	listHeight := m.height - 6
+	listWidth, previewWidth, listHeight := m.layout()
 
 	var listLines []string
 	var previewLines []string
@@ -491,22 +805,54 @@ func (m pickerModel) View() string {
 
 	// Footer
 	b.WriteString("\n")
-	if m.confirmDelete {
-		b.WriteString(confirmStyle.Render("Delete this session? (y/n)"))
+	if m.confirm != "" {
+		b.WriteString(confirmStyle.Render(m.confirmPrompt()))
+	} else if m.status != "" {
+		b.WriteString(helpStyle.Render(m.status))
 	} else {
 		switch m.mode {
 		case "newproject":
 			b.WriteString(helpStyle.Render("enter: create • esc: cancel"))
 		case "sessions":
-			b.WriteString(helpStyle.Render("enter: resume • ctrl-d: delete • ctrl-a: toggle empty • esc: back"))
+			b.WriteString(helpStyle.Render("enter: resume • tab: mark • alt-a: mark all • ctrl-d: trash • ctrl-z: undo • ctrl-e: export • ctrl-r: history • ctrl-a: toggle empty • ctrl-f: search content • ctrl-t: transcript • esc: back"))
 		default:
-			b.WriteString(helpStyle.Render("enter: resume • tab: expand • ctrl-a: toggle empty • ctrl-n: new • esc: quit"))
+			b.WriteString(helpStyle.Render("enter: resume • tab: expand • ctrl-a: toggle empty • ctrl-f: search content • ctrl-t: transcript • ctrl-n: new • esc: quit"))
 		}
 	}
 
 	return b.String()
 }
 
+// indicators returns the header badges for the active toggles
+func (m *pickerModel) indicators() string {
+	var s string
+	if m.showEmpty {
+		s += " [+empty]"
+	}
+	if m.contentSearch {
+		if m.indexing {
+			s += " [content: indexing…]"
+		} else {
+			s += " [content]"
+		}
+	}
+	if m.parsed < m.toParse {
+		s += fmt.Sprintf(" [parsed %d/%d]", m.parsed, m.toParse)
+	}
+	if m.queryErr != nil {
+		s += " [" + m.queryErr.Error() + "]"
+	}
+	return s
+}
+
+// layout returns the widths of the list and preview panes and their height
+func (m *pickerModel) layout() (listWidth, previewWidth, listHeight int) {
+	listWidth = m.width / 2
+	previewWidth = m.width - listWidth - 3
+	listHeight = m.height - 6
+	return listWidth, previewWidth, listHeight
+}
+
 func (m *pickerModel) renderProjectsMode(listWidth, previewWidth, listHeight int) ([]string, []string) {
 	var listLines []string
 	contentWidth := listWidth - 2
@@ -518,21 +864,22 @@ func (m *pickerModel) renderProjectsMode(listWidth, previewWidth, listHeight int
 
 	for i := visibleStart; i < len(m.filteredProjects) && i < visibleStart+listHeight; i++ {
 		p := m.filteredProjects[i]
-		line := formatProjectLine(p, contentWidth)
-		line = fixedWidth(line, contentWidth)
 
+		var line string
 		if i == m.projectCursor {
-			line = cursorStyle.Render("> ") + selectedStyle.Render(line)
+			line = cursorStyle.Render("> ") + formatProjectLine(p, contentWidth, m.query, selectedStyle)
 		} else {
-			line = "  " + line
+			line = "  " + formatProjectLine(p, contentWidth, m.query, plainStyle)
 		}
 		listLines = append(listLines, line)
 	}
 
 	// Preview
 	var previewLines []string
-	if len(m.filteredProjects) > 0 && m.projectCursor < len(m.filteredProjects) {
-		previewLines = formatProjectPreview(m.filteredProjects[m.projectCursor], previewWidth)
+	if m.showTranscript {
+		previewLines = m.transcriptPreview(previewWidth, listHeight)
+	} else if len(m.filteredProjects) > 0 && m.projectCursor < len(m.filteredProjects) {
+		previewLines = formatProjectPreview(m.filteredProjects[m.projectCursor], previewWidth, m.prices)
 	}
 
 	return listLines, previewLines
@@ -549,21 +896,29 @@ func (m *pickerModel) renderSessionsMode(listWidth, previewWidth, listHeight int
 
 	for i := visibleStart; i < len(m.filteredSessions) && i < visibleStart+listHeight; i++ {
 		s := m.filteredSessions[i]
-		line := formatSessionLine(s, contentWidth)
-		line = fixedWidth(line, contentWidth)
 
+		prefix := m.chainColumn(s) + m.rootColumn(s)
+		lineWidth := contentWidth - 2
+		if m.rootWidth > 0 {
+			lineWidth -= m.rootWidth + 2
+		}
+
+		var line string
 		if i == m.sessionCursor {
-			line = cursorStyle.Render("> ") + selectedStyle.Render(line)
+			line = gutter(true, m.marked[s.FilePath]) + prefix + formatSessionLine(s, lineWidth, m.query, selectedStyle)
 		} else {
-			line = "  " + line
+			line = gutter(false, m.marked[s.FilePath]) + prefix + formatSessionLine(s, lineWidth, m.query, plainStyle)
 		}
 		listLines = append(listLines, line)
 	}
 
 	// Preview
 	var previewLines []string
-	if len(m.filteredSessions) > 0 && m.sessionCursor < len(m.filteredSessions) {
-		previewLines = formatSessionPreview(m.filteredSessions[m.sessionCursor], previewWidth)
+	if m.showTranscript {
+		previewLines = m.transcriptPreview(previewWidth, listHeight)
+	} else if len(m.filteredSessions) > 0 && m.sessionCursor < len(m.filteredSessions) {
+		s := m.filteredSessions[m.sessionCursor]
+		previewLines = formatSessionPreview(s, len(m.selectedProject.History[s.FilePath]), m.rootWidth > 0, previewWidth, m.prices)
 	}
 
 	return listLines, previewLines
@@ -582,7 +937,9 @@ func (m *pickerModel) renderNewProjectMode(listWidth, previewWidth, listHeight i
 	return listLines, previewLines
 }
 
-func formatProjectLine(p ProjectGroup, maxWidth int) string {
+// formatProjectLine renders a fixed-width project line in the base style,
+// highlighting characters of the project name matched by the query
+func formatProjectLine(p ProjectGroup, maxWidth int, q fuzzy.Query, base lipgloss.Style) string {
 	sessionCount := len(p.Sessions)
 	countStr := fmt.Sprintf("%d", sessionCount)
 	if sessionCount == 1 {
@@ -590,18 +947,34 @@ func formatProjectLine(p ProjectGroup, maxWidth int) string {
 	}
 
 	// Format: "project-name        3   01/15 14:23"
-	line := fmt.Sprintf("%-20s %3s   %s", truncate(p.ProjectName, 20), countStr, p.LatestMod)
+	name := truncate(p.ProjectName, 20)
+	line := fmt.Sprintf("%-20s %3s   %s", name, countStr, p.LatestMod)
 	if len(line) > maxWidth {
 		line = line[:maxWidth-1] + "…"
 	}
-	return line
+	line = fixedWidth(line, maxWidth)
+
+	return highlight(line, q.Highlight(name), base)
 }
 
-func formatProjectPreview(p ProjectGroup, width int) []string {
+func formatProjectPreview(p ProjectGroup, width int, prices config.Prices) []string {
 	var lines []string
 
 	lines = append(lines, previewHeader.Render("Project: ")+p.ProjectName)
 	lines = append(lines, previewHeader.Render("Path: ")+p.ProjectPath)
+
+	// Older links of a resumed conversation count too
+	modelUsage := make(map[string]session.Usage)
+	for _, s := range p.Sessions {
+		for _, link := range append([]session.Session{s}, p.History[s.FilePath]...) {
+			for model, u := range link.ModelUsage {
+				total := modelUsage[model]
+				total.Add(u)
+				modelUsage[model] = total
+			}
+		}
+	}
+	lines = append(lines, usageLines(modelUsage, prices)...)
 	lines = append(lines, "")
 	lines = append(lines, previewHeader.Render("Recent Sessions:"))
 
@@ -624,7 +997,7 @@ func formatProjectPreview(p ProjectGroup, width int) []string {
 		if len(summary) > maxSummary {
 			summary = summary[:maxSummary-1] + "…"
 		}
-		line := fmt.Sprintf("  %s  %-12s  %s", s.ModTime.Format("01/02 15:04"), truncate(branch, 12), summary)
+		line := fmt.Sprintf("  %s  %-12s  %s", s.LastActivityAt.Format("01/02 15:04"), truncate(branch, 12), summary)
 		lines = append(lines, line)
 	}
 
@@ -634,30 +1007,89 @@ func formatProjectPreview(p ProjectGroup, width int) []string {
 	return lines
 }
 
-func formatSessionLine(s session.Session, maxWidth int) string {
+// formatSessionLine renders a fixed-width session line in the base style,
+// highlighting characters of the branch and summary matched by the query
+func formatSessionLine(s session.Session, maxWidth int, q fuzzy.Query, base lipgloss.Style) string {
 	branch := s.GitBranch
 	if branch == "" {
 		branch = "-"
 	}
 
-	date := s.ModTime.Format("01/02 15:04")
+	date := s.LastActivityAt.Format("01/02 15:04")
+	// Show the model most recently switched to, marking sessions that used more
+	model := "-"
+	if len(s.Models) > 0 {
+		model = truncate(shortModel(s.Models[len(s.Models)-1]), 10)
+		if len(s.Models) > 1 {
+			model = truncate(model, 9) + "+"
+		}
+	}
 	summary := s.Summary
 	if summary == "" {
 		summary = "(no summary)"
 	}
 
-	line := fmt.Sprintf("%s  %-14s  %s", date, truncate(branch, 14), summary)
+	branch = truncate(branch, 14)
+	prefix := fmt.Sprintf("%s  %-14s  %-10s  ", date, branch, model)
+	line := prefix + summary
 	if len(line) > maxWidth {
 		line = line[:maxWidth-1] + "…"
 	}
-	return line
+	line = fixedWidth(line, maxWidth)
+
+	branchStart := len([]rune(date)) + 2
+	positions := offsetPositions(q.Highlight(branch), branchStart)
+	positions = append(positions, offsetPositions(q.Highlight(summary), len([]rune(prefix)))...)
+	return highlight(line, positions, base)
+}
+
+func offsetPositions(positions []int, offset int) []int {
+	out := make([]int, len(positions))
+	for i, p := range positions {
+		out[i] = p + offset
+	}
+	return out
+}
+
+// highlight renders line in the base style, with the runes at the given
+// positions emphasized. Runs are rendered separately so the base style
+// (e.g. the selection background) isn't reset by a highlight.
+func highlight(line string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(line)
+	}
+
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+	emphasis := base.Copy().Foreground(matchColor).Bold(true)
+
+	var b strings.Builder
+	runes := []rune(line)
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			b.WriteString(emphasis.Render(string(runes[start:end])))
+		} else {
+			b.WriteString(base.Render(string(runes[start:end])))
+		}
+		start = end
+	}
+	return b.String()
 }
 
-func formatSessionPreview(s session.Session, width int) []string {
+func formatSessionPreview(s session.Session, earlier int, showRoot bool, width int, prices config.Prices) []string {
 	var lines []string
 
 	lines = append(lines, previewHeader.Render("Session: ")+s.ID)
 	lines = append(lines, previewHeader.Render("Project: ")+s.ProjectPath)
+	if showRoot {
+		lines = append(lines, previewHeader.Render("Root: ")+s.Root)
+	}
 	lines = append(lines, "")
 
 	if s.Summary != "" && s.Summary != "(no summary)" {
@@ -670,9 +1102,50 @@ func formatSessionPreview(s session.Session, width int) []string {
 	if s.GitBranch != "" {
 		lines = append(lines, previewHeader.Render("Branch: ")+s.GitBranch)
 	}
+	if len(s.Models) > 0 {
+		lines = append(lines, previewHeader.Render("Models: ")+strings.Join(s.Models, ", "))
+	}
+
+	if earlier == 1 {
+		lines = append(lines, "Continues 1 earlier session (ctrl-r)")
+	} else if earlier > 1 {
+		lines = append(lines, fmt.Sprintf("Continues %d earlier sessions (ctrl-r)", earlier))
+	}
 
 	lines = append(lines, fmt.Sprintf("Messages: %d user / %d assistant", s.UserMsgCount, s.AsstMsgCount))
-	lines = append(lines, dimStyle.Render("Modified: "+s.ModTime.Format("2006-01-02 15:04:05")))
+	if s.Branches > 1 {
+		lines = append(lines, fmt.Sprintf("Branches: %d (showing counts for the latest)", s.Branches))
+	}
+	if s.ToolCallCount > 0 || s.ToolResultCount > 0 {
+		lines = append(lines, fmt.Sprintf("Tools: %d calls / %d results", s.ToolCallCount, s.ToolResultCount))
+	}
+	if n := len(s.Subagents); n > 0 {
+		label := "subagent runs"
+		if n == 1 {
+			label = "subagent run"
+		}
+		lines = append(lines, fmt.Sprintf("%d %s:", n, label))
+		for i, sub := range s.Subagents {
+			if i >= 5 {
+				lines = append(lines, dimStyle.Render(fmt.Sprintf("  ... and %d more", n-5)))
+				break
+			}
+			prompt := strings.Join(strings.Fields(sub.Prompt), " ")
+			lines = append(lines, dimStyle.Render(truncate("  ▸ "+prompt, width)))
+		}
+	}
+	lines = append(lines, usageLines(s.ModelUsage, prices)...)
+	lines = append(lines, dimStyle.Render("Started: "+s.StartedAt.Format("2006-01-02 15:04:05")))
+	lines = append(lines, dimStyle.Render("Last activity: "+s.LastActivityAt.Format("2006-01-02 15:04:05")))
+	if d := s.Duration(); d > 0 {
+		lines = append(lines, dimStyle.Render("Duration: "+formatDuration(d)))
+	}
+	if len(s.Warnings) > 0 {
+		lines = append(lines, "", warnStyle.Render("Parse warnings:"))
+		for _, w := range s.Warnings {
+			lines = append(lines, dimStyle.Render(truncate("  "+w, width)))
+		}
+	}
 
 	return lines
 }
@@ -712,6 +1185,24 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-1] + "…"
 }
 
+// modelDate matches the release date suffix of model names
+var modelDate = regexp.MustCompile(`-\d{8}$`)
+
+// shortModel trims a model name for the session list, e.g.
+// claude-sonnet-4-5-20250929 becomes sonnet-4-5
+func shortModel(model string) string {
+	return modelDate.ReplaceAllString(strings.TrimPrefix(model, "claude-"), "")
+}
+
+// formatDuration renders a duration to the second under a minute and to
+// the minute above, e.g. 45s, 12m, 1h5m
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
 func fixedWidth(s string, width int) string {
 	runes := []rune(s)
 	if len(runes) > width {
@@ -784,12 +1275,12 @@ func (m *pickerModel) loadExistingDirs() {
 }
 
 // SelectSession runs the interactive picker and returns the result
-func SelectSession(sessions []session.Session, showEmpty bool, projectsDir string) (Result, error) {
-	if len(sessions) == 0 {
+func SelectSession(sessions []session.Session, opts Options) (Result, error) {
+	if len(sessions) == 0 && opts.Updates == nil {
 		return Result{}, fmt.Errorf("no sessions found")
 	}
 
-	m := newPickerModel(sessions, showEmpty, projectsDir)
+	m := newPickerModel(sessions, opts)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	finalModel, err := p.Run()
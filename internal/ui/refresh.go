@@ -0,0 +1,87 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+// sessionsMsg delivers sessions scanned while the picker is open: more of
+// the startup scan, or a rescan after files changed
+type sessionsMsg struct {
+	progress session.Progress
+}
+
+// waitForSessions waits for the next scan, if the picker gets any
+func (m *pickerModel) waitForSessions() tea.Cmd {
+	updates := m.updates
+	if updates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		progress, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return sessionsMsg{progress: progress}
+	}
+}
+
+// setSessions replaces the sessions with a newer scan, keeping the cursor on
+// the same project or session when it is still there
+func (m *pickerModel) setSessions(sessions []session.Session) {
+	var projectPath, sessionPath string
+	if m.projectCursor < len(m.filteredProjects) {
+		projectPath = m.filteredProjects[m.projectCursor].ProjectPath
+	}
+	if m.sessionCursor < len(m.filteredSessions) {
+		sessionPath = m.filteredSessions[m.sessionCursor].FilePath
+	}
+	var previewed session.Session
+	if s := m.previewSession(); s != nil {
+		previewed = *s
+	}
+
+	m.allSessions = sessions
+	m.rebuildProjects()
+
+	present := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		present[s.FilePath] = true
+	}
+	for path := range m.marked {
+		if !present[path] {
+			delete(m.marked, path)
+		}
+	}
+
+	if m.mode == "sessions" {
+		m.refreshSelectedProject()
+		if m.selectedProject == nil {
+			m.mode = "projects"
+		} else {
+			m.applySessionFilter()
+			for i, s := range m.filteredSessions {
+				if s.FilePath == sessionPath {
+					m.sessionCursor = i
+					break
+				}
+			}
+			m.sessionCursor = min(m.sessionCursor, max(0, len(m.filteredSessions)-1))
+		}
+	}
+	if m.mode == "projects" {
+		m.applyProjectFilter()
+		for i, p := range m.filteredProjects {
+			if p.ProjectPath == projectPath {
+				m.projectCursor = i
+				break
+			}
+		}
+	}
+
+	// Reload the transcript pane if its session was written to
+	if s := m.previewSession(); s != nil && s.FilePath == previewed.FilePath && !s.ModTime.Equal(previewed.ModTime) {
+		m.transcript.path = ""
+	}
+}
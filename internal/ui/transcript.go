@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jh3/claude-fzf/internal/session"
+)
+
+var (
+	userHeader      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	assistantHeader = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	subagentRule    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+// toolInputKeys are the tool input fields worth showing on a collapsed
+// tool call line, in order of preference
+var toolInputKeys = []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"}
+
+// transcriptState holds the lazily loaded transcript of the previewed session
+type transcriptState struct {
+	path      string
+	expanded  bool // subagent transcripts were loaded
+	messages  []session.Message
+	subagents map[string][]session.Message // by the tool call that started them
+	err       error
+	loaded    bool
+	scroll    int
+
+	// Conversation branches: the leaf of each, and the one shown
+	leaves    []string
+	branch    int
+	thread    []session.Message // messages on the shown branch
+	formatted *transcriptLines  // thread as last formatted; replaced when thread changes
+}
+
+// transcriptLines caches a formatted thread, which would otherwise be
+// re-wrapped on every frame and scroll. It is held by pointer so that
+// View, which renders a copy of the model, can fill it in.
+type transcriptLines struct {
+	width int
+	lines []string
+}
+
+// lines returns the shown thread formatted to width
+func (t *transcriptState) lines(width int) []string {
+	c := t.formatted
+	if c == nil {
+		return formatTranscript(t.thread, t.subagents, width)
+	}
+	if c.lines == nil || c.width != width {
+		c.lines = formatTranscript(t.thread, t.subagents, width)
+		c.width = width
+	}
+	return c.lines
+}
+
+// transcriptLoadedMsg delivers a transcript loaded in the background
+type transcriptLoadedMsg struct {
+	path      string
+	expanded  bool
+	messages  []session.Message
+	subagents map[string][]session.Message
+	err       error
+}
+
+// loadTranscript loads a session's transcript and, when expand is set, the
+// transcripts of the subagent runs it started
+func loadTranscript(s session.Session, expand bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := transcriptLoadedMsg{path: s.FilePath, expanded: expand}
+		msg.messages, msg.err = session.LoadTranscript(s.FilePath)
+		if msg.err != nil || !expand || len(s.Subagents) == 0 {
+			return msg
+		}
+		msg.subagents = make(map[string][]session.Message)
+		for callID, sub := range session.MatchSubagents(msg.messages, s.Subagents) {
+			if messages, err := session.LoadTranscript(sub.FilePath); err == nil {
+				msg.subagents[callID] = messages
+			}
+		}
+		return msg
+	}
+}
+
+// previewSession returns the session shown in the preview: the highlighted
+// session, or the most recent session of the highlighted project
+func (m *pickerModel) previewSession() *session.Session {
+	switch m.mode {
+	case "sessions":
+		if m.sessionCursor < len(m.filteredSessions) {
+			return &m.filteredSessions[m.sessionCursor]
+		}
+	case "projects":
+		if m.projectCursor < len(m.filteredProjects) {
+			return &m.filteredProjects[m.projectCursor].Sessions[0]
+		}
+	}
+	return nil
+}
+
+// syncTranscript starts loading the previewed session's transcript when the
+// transcript pane is open and the highlighted session or the subagent
+// expansion has changed
+func (m *pickerModel) syncTranscript() tea.Cmd {
+	if !m.showTranscript {
+		return nil
+	}
+	s := m.previewSession()
+	if s == nil || (s.FilePath == m.transcript.path && m.expandSubagents == m.transcript.expanded) {
+		return nil
+	}
+	m.transcript = transcriptState{path: s.FilePath, expanded: m.expandSubagents}
+	return loadTranscript(*s, m.expandSubagents)
+}
+
+// scrollTranscript moves the transcript pane by delta lines, leaving the
+// list cursor alone
+func (m *pickerModel) scrollTranscript(delta int) {
+	if !m.showTranscript || !m.transcript.loaded {
+		return
+	}
+	_, previewWidth, listHeight := m.layout()
+	lines := m.transcript.lines(previewWidth)
+	maxScroll := max(0, len(lines)-(listHeight-1))
+	m.transcript.scroll = min(max(0, m.transcript.scroll+delta), maxScroll)
+}
+
+// setTranscript stores a loaded transcript, showing its active branch
+func (m *pickerModel) setTranscript(msg transcriptLoadedMsg) {
+	m.transcript.messages = msg.messages
+	m.transcript.subagents = msg.subagents
+	m.transcript.err = msg.err
+	m.transcript.loaded = true
+	m.transcript.leaves = session.Leaves(msg.messages)
+	m.transcript.branch = max(0, len(m.transcript.leaves)-1)
+	m.transcript.thread = session.ActiveThread(msg.messages)
+	m.transcript.formatted = &transcriptLines{}
+}
+
+// cycleBranch shows the previous conversation branch of the transcript,
+// wrapping around from the oldest to the active one
+func (m *pickerModel) cycleBranch() {
+	n := len(m.transcript.leaves)
+	if !m.showTranscript || !m.transcript.loaded || n < 2 {
+		return
+	}
+	t := &m.transcript
+	t.branch = (t.branch - 1 + n) % n
+	t.thread = session.Thread(t.messages, t.leaves[t.branch])
+	t.formatted = &transcriptLines{}
+	t.scroll = 0
+	if t.branch == n-1 {
+		m.status = fmt.Sprintf("Branch %d/%d (active)", t.branch+1, n)
+	} else {
+		m.status = fmt.Sprintf("Branch %d/%d", t.branch+1, n)
+	}
+}
+
+// transcriptPreview renders the visible window of the transcript pane
+func (m *pickerModel) transcriptPreview(width, height int) []string {
+	if !m.transcript.loaded {
+		return []string{dimStyle.Render("Loading transcript...")}
+	}
+	if m.transcript.err != nil {
+		return []string{confirmStyle.Render("Error: " + m.transcript.err.Error())}
+	}
+
+	lines := m.transcript.lines(width)
+	if len(lines) == 0 {
+		return []string{dimStyle.Render("(empty transcript)")}
+	}
+
+	body := height - 1
+	start := min(m.transcript.scroll, max(0, len(lines)-body))
+	end := min(len(lines), start+body)
+	header := fmt.Sprintf("Transcript %d-%d/%d • shift-↑/↓: scroll • ctrl-o: subagents", start+1, end, len(lines))
+	if n := len(m.transcript.leaves); n > 1 {
+		header += fmt.Sprintf(" • branch %d/%d (ctrl-b)", m.transcript.branch+1, n)
+	}
+	header = dimStyle.Render(header)
+	return append([]string{header}, lines[start:end]...)
+}
+
+// formatTranscript renders user and assistant turns under role headers.
+// Consecutive lines from the same role share a header, tool calls are
+// collapsed to one line each, and tool results are omitted. Subagent
+// transcripts, keyed by the tool call that started them, are inlined
+// below that call behind a rule.
+func formatTranscript(messages []session.Message, subagents map[string][]session.Message, width int) []string {
+	width = max(width, 1) // the preview pane has no room on a narrow terminal
+	var lines []string
+	prevRole := ""
+
+	for _, msg := range messages {
+		if msg.Text == "" && len(msg.ToolCalls) == 0 {
+			continue
+		}
+		if msg.Role != prevRole {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, roleHeader(msg.Role))
+			prevRole = msg.Role
+		}
+		if msg.Text != "" {
+			lines = append(lines, wrapText(msg.Text, width)...)
+		}
+		for _, call := range msg.ToolCalls {
+			lines = append(lines, dimStyle.Render(truncate("▸ "+toolCallSummary(call), width)))
+			if sub, ok := subagents[call.ID]; ok && width > 4 {
+				for _, line := range formatTranscript(sub, nil, width-2) {
+					if line != "" {
+						line = " " + line
+					}
+					lines = append(lines, subagentRule.Render("│")+line)
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func roleHeader(role string) string {
+	if role == "user" {
+		return userHeader.Render("── You ──")
+	}
+	return assistantHeader.Render("── Claude ──")
+}
+
+// toolCallSummary renders a tool call on one line, e.g. "Bash: go test ./..."
+func toolCallSummary(call session.ToolCall) string {
+	var input map[string]any
+	json.Unmarshal(call.Input, &input)
+	for _, key := range toolInputKeys {
+		if v, ok := input[key].(string); ok && v != "" {
+			return call.Name + ": " + strings.Join(strings.Fields(v), " ")
+		}
+	}
+	return call.Name
+}
+
+// wrapText wraps multi-line text to width, keeping line breaks and
+// hard-breaking words that are longer than a line
+func wrapText(text string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	for _, para := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.TrimSpace(para) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		for _, line := range wordWrap(para, width) {
+			runes := []rune(line)
+			for len(runes) > width {
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			lines = append(lines, string(runes))
+		}
+	}
+	return lines
+}
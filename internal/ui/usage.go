@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jh3/claude-fzf/internal/config"
+	"github.com/jh3/claude-fzf/internal/session"
+	"github.com/jh3/claude-fzf/internal/stats"
+)
+
+// usageLines renders token totals and the estimated cost for preview
+// panes, with a per-model breakdown when more than one model was used.
+// Returns nil when no usage was recorded.
+func usageLines(modelUsage map[string]session.Usage, prices config.Prices) []string {
+	var total session.Usage
+	for _, u := range modelUsage {
+		total.Add(u)
+	}
+	if total.Total() == 0 {
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("Tokens: %s (%s in / %s out / %s cache read / %s cache write)",
+		stats.FormatCount(total.Total()),
+		stats.FormatCount(total.InputTokens),
+		stats.FormatCount(total.OutputTokens),
+		stats.FormatCount(total.CacheReadTokens),
+		stats.FormatCount(total.CacheCreationTokens))}
+
+	if len(modelUsage) > 1 {
+		models := make([]string, 0, len(modelUsage))
+		for model := range modelUsage {
+			models = append(models, model)
+		}
+		sort.Slice(models, func(i, j int) bool {
+			return modelUsage[models[i]].Total() > modelUsage[models[j]].Total()
+		})
+		for _, model := range models {
+			cost := "unpriced"
+			if _, ok := prices.Lookup(model); ok {
+				usd, _ := prices.Cost(map[string]session.Usage{model: modelUsage[model]})
+				cost = formatCost(usd)
+			}
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("  %s  %s  %s",
+				model, stats.FormatCount(modelUsage[model].Total()), cost)))
+		}
+	}
+
+	cost, complete := prices.Cost(modelUsage)
+	line := "Est. cost: " + formatCost(cost)
+	if !complete {
+		line += dimStyle.Render(" (some models unpriced)")
+	}
+	return append(lines, line)
+}
+
+func formatCost(usd float64) string {
+	if usd > 0 && usd < 0.01 {
+		return "<$0.01"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
@@ -0,0 +1,137 @@
+// Package watch reports changes to session files while the picker is open.
+package watch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// settle is how long writes must stop before a change is reported, so a
+// burst of appended lines is reported once
+const settle = 300 * time.Millisecond
+
+// maxWait is the longest a change waits for writes to settle, so a session
+// that is being written to without pause is still reported
+const maxWait = 2 * time.Second
+
+// Watcher watches projects directories and every directory below them
+// (projects, and subagent directories in those) for session files being
+// written, created or removed
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	changes chan struct{}
+}
+
+// New starts watching dirs. Directories that can't be watched (a session
+// root that has no sessions yet, say) are skipped; it fails only if none
+// can be.
+func New(dirs ...string) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{fs: fs, changes: make(chan struct{}, 1)}
+	watched := 0
+	for _, dir := range dirs {
+		if err = w.addTree(dir); err == nil {
+			watched++
+		}
+	}
+	if watched == 0 {
+		fs.Close()
+		if err == nil {
+			err = errors.New("no directories to watch")
+		}
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Changes receives a value once session files have changed and settled.
+// Changes made before the last one was received are folded into it. The
+// channel is closed by Close.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+func (w *Watcher) run() {
+	defer close(w.changes)
+
+	timer := time.NewTimer(settle)
+	timer.Stop()
+	var first time.Time // when the first change not yet reported was seen
+	changed := func() {
+		now := time.Now()
+		if first.IsZero() {
+			first = now
+		}
+		timer.Reset(min(settle, first.Add(maxWait).Sub(now)))
+	}
+	for {
+		select {
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if newDir(ev) {
+				// Sessions may have been written in it before the watch
+				// was added
+				w.addTree(ev.Name)
+				changed()
+				continue
+			}
+			if strings.HasSuffix(ev.Name, ".jsonl") && ev.Op != fsnotify.Chmod {
+				changed()
+			}
+
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+
+		case <-timer.C:
+			first = time.Time{}
+			select {
+			case w.changes <- struct{}{}:
+			default: // a change is already waiting to be received
+			}
+		}
+	}
+}
+
+// addTree watches dir and every directory below it. Subdirectories that
+// can't be watched are skipped.
+func (w *Watcher) addTree(dir string) error {
+	if err := w.fs.Add(dir); err != nil {
+		return err
+	}
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != dir {
+			w.fs.Add(path)
+		}
+		return nil
+	})
+	return nil
+}
+
+// newDir reports whether ev created a directory
+func newDir(ev fsnotify.Event) bool {
+	if !ev.Has(fsnotify.Create) {
+		return false
+	}
+	info, err := os.Stat(ev.Name)
+	return err == nil && info.IsDir()
+}